@@ -0,0 +1,346 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a prefilled answer besides environment variables
+const (
+	sourceBuiltin     = "built-in default"
+	sourceAnswersFile = "answers file" // --answers
+	sourceUserConfig  = "user config"
+	sourceOrgConfig   = "org config"
+	sourceAnswer      = "answer" // asked in this run
+)
+
+// answerSources records where the value of each answer came from, keyed by review field name
+var answerSources = map[string]string{}
+
+// envAnswers are the environment variables that prefill the answers, the same names hooks and plugins receive
+var envAnswers = []struct {
+	Field string
+	Env   string
+}{
+	{"dir", "WEBCORE_PROJECT_DIR"},
+	{"module", "WEBCORE_MODULE_NAME"},
+	{"libraries", "WEBCORE_LIBRARIES"},
+	{"mode", "WEBCORE_MODE"},
+	{"folder", "WEBCORE_FOLDER"},
+	{"package", "WEBCORE_PACKAGE"},
+	{"module-mod", "WEBCORE_MODULE_MOD_NAME"},
+	{"features", "WEBCORE_FEATURES"},
+	{"git", "WEBCORE_GIT_INIT"},
+	{"git-branch", "WEBCORE_GIT_BRANCH"},
+	{"git-remote", "WEBCORE_GIT_REMOTE"},
+	{"git-commit", "WEBCORE_GIT_COMMIT"},
+	{"git-commit-message", "WEBCORE_GIT_COMMIT_MESSAGE"},
+	{"git-author", "WEBCORE_GIT_AUTHOR"},
+	{"dockerfile", "WEBCORE_DOCKERFILE"},
+	{"ci", "WEBCORE_CI"},
+	{"kubernetes", "WEBCORE_KUBERNETES"},
+	{"service-config", "WEBCORE_SERVICE_CONFIG"},
+	{"env-file", "WEBCORE_ENV_FILE"},
+}
+
+// applyEnvAnswers prefills config from the WEBCORE_* environment variables that are set
+func applyEnvAnswers(config *Config) error {
+	for _, e := range envAnswers {
+		value := strings.TrimSpace(os.Getenv(e.Env))
+		if value == "" {
+			continue
+		}
+
+		if err := setAnswer(config, e.Field, value); err != nil {
+			return invalidInputError(fmt.Sprintf("fix or unset %s", e.Env), fmt.Errorf("invalid %s: %w", e.Env, err))
+		}
+		answerSources[e.Field] = e.Env
+	}
+	return nil
+}
+
+// applyAnswersFile prefills the answers that no environment variable set from a YAML file of answers,
+// keyed by the same field names as envAnswers. Lists can be written as YAML sequences.
+func applyAnswersFile(config *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return invalidInputError("check the --answers path", fmt.Errorf("failed to read the answers file: %w", err))
+	}
+	answers := map[string]any{}
+	if err := yaml.Unmarshal(content, &answers); err != nil {
+		return invalidInputError(fmt.Sprintf("fix the YAML of %s", path), fmt.Errorf("failed to parse %s: %w", path, err))
+	}
+
+	known := make(map[string]bool, len(envAnswers))
+	for _, e := range envAnswers {
+		known[e.Field] = true
+	}
+	for _, field := range sortedKeys(answers) {
+		if !known[field] {
+			return invalidInputError(fmt.Sprintf("fix %s", path), fmt.Errorf("unknown answer %q in %s", field, path))
+		}
+	}
+
+	for _, e := range envAnswers {
+		raw, ok := answers[e.Field]
+		if !ok || answerSources[e.Field] != "" {
+			continue
+		}
+		if err := setAnswer(config, e.Field, answerText(raw)); err != nil {
+			return invalidInputError(fmt.Sprintf("fix %s in %s", e.Field, path), fmt.Errorf("invalid %s in %s: %w", e.Field, path, err))
+		}
+		answerSources[e.Field] = sourceAnswersFile
+	}
+	return nil
+}
+
+// answerText converts an answer of the answers file to the text of an environment variable, lists become comma separated
+func answerText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// applyDefaultAnswers prefills the answers that aren't set yet with the defaults of the user config file,
+// then with those of the organization config file
+func applyDefaultAnswers(config *Config) error {
+	userConfig, err := loadUserConfig()
+	if err != nil {
+		return invalidInputError("fix the user config file", err)
+	}
+	orgConfig, err := loadOrgConfig()
+	if err != nil {
+		return invalidInputError(fmt.Sprintf("fix the file or unset %s", orgConfigEnv), err)
+	}
+
+	if err := applyDefaults(config, userConfig, sourceUserConfig); err != nil {
+		return err
+	}
+	return applyDefaults(config, orgConfig, sourceOrgConfig)
+}
+
+// applyDefaults prefills the answers that aren't set yet with the defaults of a config file
+func applyDefaults(config *Config, file *userConfig, source string) error {
+	defaults := file.Defaults
+	values := map[string]string{}
+	if defaults.ModulePrefix != "" {
+		values["module"] = prefixedModuleName(defaults.ModulePrefix, config.ProjectDir)
+	}
+	if defaults.Libraries != nil {
+		values["libraries"] = strings.Join(defaults.Libraries, ",")
+	}
+	if defaults.Mode != "" {
+		values["mode"] = defaults.Mode
+	}
+	if defaults.Features != nil {
+		values["features"] = strings.Join(defaults.Features, ",")
+	}
+	if defaults.GitInit != nil {
+		values["git"] = strconv.FormatBool(*defaults.GitInit)
+	}
+	if defaults.GitBranch != "" {
+		values["git-branch"] = defaults.GitBranch
+	}
+	if defaults.GitCommit != nil {
+		values["git-commit"] = strconv.FormatBool(*defaults.GitCommit)
+	}
+	if defaults.GitCommitMessage != "" {
+		values["git-commit-message"] = defaults.GitCommitMessage
+	}
+	if defaults.GitAuthor != "" {
+		values["git-author"] = defaults.GitAuthor
+	}
+	if defaults.Dockerfile != nil {
+		values["dockerfile"] = strconv.FormatBool(*defaults.Dockerfile)
+	}
+	if defaults.CI != "" {
+		values["ci"] = defaults.CI
+	}
+	if defaults.Kubernetes != "" {
+		values["kubernetes"] = defaults.Kubernetes
+	}
+	if defaults.ServiceConfig != nil {
+		values["service-config"] = strconv.FormatBool(*defaults.ServiceConfig)
+	}
+	if defaults.EnvFile != nil {
+		values["env-file"] = strconv.FormatBool(*defaults.EnvFile)
+	}
+
+	for _, field := range []string{"module", "libraries", "mode", "features", "git", "git-branch", "git-commit", "git-commit-message", "git-author", "dockerfile", "ci", "kubernetes", "service-config", "env-file"} {
+		value, ok := values[field]
+		if !ok || answerSources[field] != "" {
+			continue
+		}
+		if err := setAnswer(config, field, value); err != nil {
+			return invalidInputError(fmt.Sprintf("fix the defaults in %s", file.path), fmt.Errorf("invalid default %s in %s: %w", field, file.path, err))
+		}
+		answerSources[field] = source
+	}
+
+	if defaults.Template != "" && templateSource == templateRepoURL {
+		templateSource = defaults.Template
+	}
+	return nil
+}
+
+// applyInferredModuleName prefills the module name derived from the surrounding repository,
+// only when no flag, environment variable, answers file or config file set it
+func applyInferredModuleName(config *Config) {
+	if answerSources["module"] != "" {
+		return
+	}
+	if moduleName, source := inferModuleName(config.ProjectDir); moduleName != "" {
+		config.ModuleName = moduleName
+		answerSources["module"] = source
+	}
+}
+
+// prefixedModuleName appends the name of the project directory to a module prefix (github.com/yourorg becomes github.com/yourorg/webcore)
+func prefixedModuleName(prefix, projectDir string) string {
+	name := filepath.Base(projectDir)
+	if abs, err := filepath.Abs(projectDir); err == nil {
+		name = filepath.Base(abs)
+	}
+	return path.Join(strings.TrimSuffix(prefix, "/"), strings.ToLower(name))
+}
+
+// setAnswer parses value into the config field
+func setAnswer(config *Config, field, value string) error {
+	var err error
+	switch field {
+	case "dir":
+		config.ProjectDir = cleanProjectDir(value)
+	case "module":
+		config.ModuleName = value
+	case "libraries":
+		config.SelectedLibraries, err = parseLibraryNames(splitList(value))
+	case "mode":
+		if value != "simple" && value != "mono-repo" {
+			return fmt.Errorf("unknown mode %q, expected simple or mono-repo", value)
+		}
+		config.ProjectMode = value
+	case "folder":
+		config.FolderName = value
+	case "package":
+		config.PackageName = value
+	case "module-mod":
+		config.ModuleModName = value
+	case "features":
+		config.SelectedFeatures, err = parseFeatureNames(splitList(value))
+	case "git":
+		config.GitInit, err = strconv.ParseBool(value)
+	case "git-branch":
+		err = validateBranchName(value)
+		config.GitBranch = value
+	case "git-remote":
+		config.GitRemote = value
+	case "git-commit":
+		config.GitCommit, err = strconv.ParseBool(value)
+	case "git-commit-message":
+		config.GitCommitMessage = value
+	case "git-author":
+		_, _, err = parseGitAuthor(value)
+		config.GitAuthor = value
+	case "dockerfile":
+		config.Dockerfile, err = strconv.ParseBool(value)
+	case "ci":
+		config.CI, err = parseCIProvider(value)
+	case "kubernetes":
+		config.Kubernetes, err = parseKubernetesFormat(value)
+	case "service-config":
+		config.ServiceConfig, err = strconv.ParseBool(value)
+	case "env-file":
+		config.SecretsEnvFile, err = strconv.ParseBool(value)
+	}
+	return err
+}
+
+// splitList splits a comma separated list and drops empty entries
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseLibraryNames looks up the libraries of the catalog by name
+func parseLibraryNames(names []string) ([]LibraryOption, error) {
+	libraries := make([]LibraryOption, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, lib := range availableLibraries {
+			if lib.Name == name {
+				libraries = append(libraries, lib)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown library %q", name)
+		}
+	}
+	return libraries, nil
+}
+
+// parseFeatureNames looks up the features by name
+func parseFeatureNames(names []string) ([]Feature, error) {
+	features := make([]Feature, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, feature := range availableFeatures {
+			if feature.Name == name {
+				features = append(features, feature)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown feature %q", name)
+		}
+	}
+	return features, nil
+}
+
+// isFlagSource checks if an answer was set by a command-line flag
+func isFlagSource(source string) bool {
+	return strings.HasPrefix(source, "--")
+}
+
+// isEnvSource checks if an answer was set by a WEBCORE_* environment variable
+func isEnvSource(source string) bool {
+	return strings.HasPrefix(source, "WEBCORE_")
+}
+
+// answerSource returns where the answer of field came from
+func answerSource(field string) string {
+	if source := answerSources[field]; source != "" {
+		return source
+	}
+	return sourceBuiltin
+}
+
+// withSource adds where the prefilled value came from to a prompt message
+func withSource(message, field string) string {
+	if source := answerSources[field]; source != "" && source != sourceAnswer {
+		return fmt.Sprintf("%s (prefilled from %s)", message, source)
+	}
+	return message
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// resetAnswerSources clears the recorded answer sources for a test
+func resetAnswerSources(t *testing.T) {
+	t.Helper()
+	saved := answerSources
+	answerSources = map[string]string{}
+	t.Cleanup(func() { answerSources = saved })
+}
+
+// writeTestFile writes content to name under dir, creating its directories
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAnswerPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string // WEBCORE_MODULE_NAME
+		answers    string // answers file
+		prefix     string // module_prefix of the user config file
+		wantModule string
+		wantSource string
+	}{
+		{"env wins", "github.com/env/svc", "module: github.com/file/svc\n", "github.com/user", "github.com/env/svc", "WEBCORE_MODULE_NAME"},
+		{"answers file over defaults", "", "module: github.com/file/svc\n", "github.com/user", "github.com/file/svc", sourceAnswersFile},
+		{"user config over inferred", "", "", "github.com/user", "github.com/user/billing", sourceUserConfig},
+		{"inferred when unset", "", "", "", "github.com/parent/platform/billing", "parent go.mod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAnswerSources(t)
+			root := t.TempDir()
+			writeTestFile(t, root, "go.mod", "module github.com/parent/platform\n")
+			projectDir := filepath.Join(root, "billing")
+
+			configHome := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", configHome)
+			t.Setenv(orgConfigEnv, "")
+			if tt.prefix != "" {
+				writeTestFile(t, configHome, "webcore-go-install/config.yaml", "defaults:\n  module_prefix: "+tt.prefix+"\n")
+			}
+			t.Setenv("WEBCORE_MODULE_NAME", tt.env)
+
+			config := &Config{ProjectDir: projectDir}
+			if err := applyEnvAnswers(config); err != nil {
+				t.Fatal(err)
+			}
+			if tt.answers != "" {
+				if err := applyAnswersFile(config, writeTestFile(t, root, "answers.yaml", tt.answers)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := applyDefaultAnswers(config); err != nil {
+				t.Fatal(err)
+			}
+			applyInferredModuleName(config)
+
+			if config.ModuleName != tt.wantModule || answerSources["module"] != tt.wantSource {
+				t.Errorf("module = %q from %q, want %q from %q", config.ModuleName, answerSources["module"], tt.wantModule, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestAnswersFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"lists as sequences", "libraries: [redis, pubsub]\nmode: simple\ngit: true\n", false},
+		{"lists as text", "libraries: redis,pubsub\n", false},
+		{"unknown key", "modul: github.com/acme/svc\n", true},
+		{"invalid value", "mode: monorepo\n", true},
+		{"unknown library", "libraries: [redis, nosql]\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAnswerSources(t)
+			config := &Config{}
+			err := applyAnswersFile(config, writeTestFile(t, t.TempDir(), "answers.yaml", tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && answerSources["libraries"] == sourceAnswersFile && len(config.SelectedLibraries) != 2 {
+				t.Errorf("libraries = %v, want redis and pubsub", config.SelectedLibraries)
+			}
+		})
+	}
+}
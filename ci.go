@@ -0,0 +1,489 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yarlson/tap"
+	"gopkg.in/yaml.v3"
+)
+
+// CI providers
+const (
+	ciGitHub   = "github"
+	ciGitLab   = "gitlab"
+	ciMakefile = "makefile"
+	ciNone     = "none"
+)
+
+// ciFiles are the files written for each CI provider, relative to the project directory
+var ciFiles = map[string]string{
+	ciGitHub:   filepath.Join(".github", "workflows", "ci.yaml"),
+	ciGitLab:   ".gitlab-ci.yml",
+	ciMakefile: "ci.mk",
+}
+
+// ciPassword is the password of the services in CI, the CI files are committed so they never get the one of config.yaml
+const ciPassword = "webcore-ci"
+
+// defaultConnectionKeys are the connection settings of a section when config.yaml doesn't enable it
+var defaultConnectionKeys = map[string][]string{
+	"database": {"host", "port"},
+	"redis":    {"address"},
+	"kafka":    {"brokers"},
+}
+
+// githubWorkflow is the generated GitHub Actions workflow
+type githubWorkflow struct {
+	Name string               `yaml:"name"`
+	On   githubTriggers       `yaml:"on"`
+	Env  map[string]string    `yaml:"env,omitempty"`
+	Jobs map[string]githubJob `yaml:"jobs"`
+}
+
+type githubTriggers struct {
+	Push        githubBranches `yaml:"push"`
+	PullRequest githubBranches `yaml:"pull_request"`
+}
+
+type githubBranches struct {
+	Branches []string `yaml:"branches"`
+}
+
+type githubJob struct {
+	RunsOn   string                   `yaml:"runs-on"`
+	Services map[string]githubService `yaml:"services,omitempty"`
+	Steps    []githubStep             `yaml:"steps"`
+}
+
+type githubService struct {
+	Image   string            `yaml:"image"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	Ports   []string          `yaml:"ports,omitempty"`
+	Options string            `yaml:"options,omitempty"`
+}
+
+type githubStep struct {
+	Name             string            `yaml:"name,omitempty"`
+	Uses             string            `yaml:"uses,omitempty"`
+	With             map[string]string `yaml:"with,omitempty"`
+	WorkingDirectory string            `yaml:"working-directory,omitempty"`
+	Run              string            `yaml:"run,omitempty"`
+}
+
+// gitlabPipeline is the generated GitLab CI pipeline
+type gitlabPipeline struct {
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Test      gitlabJob         `yaml:"test"`
+}
+
+type gitlabJob struct {
+	Image     string            `yaml:"image"`
+	Services  []gitlabService   `yaml:"services,omitempty"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Script    []string          `yaml:"script"`
+}
+
+type gitlabService struct {
+	Name      string            `yaml:"name"`
+	Alias     string            `yaml:"alias"`
+	Command   []string          `yaml:"command,omitempty"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+}
+
+// parseCIProvider validates a CI provider name, an empty name means none
+func parseCIProvider(value string) (string, error) {
+	switch value {
+	case ciGitHub, ciGitLab, ciMakefile:
+		return value, nil
+	case "", ciNone:
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown CI provider %q, expected github, gitlab, makefile or none", value)
+}
+
+// selectCIProvider asks for the CI provider, preselecting current
+func selectCIProvider(ctx context.Context, current string) string {
+	initialValue := ciNone
+	if current != "" {
+		initialValue = current
+	}
+	provider := promptSelect(ctx, tap.SelectOptions[string]{
+		Message: withSource("Generate a CI pipeline?", "ci"),
+		Options: []tap.SelectOption[string]{
+			{Label: "No CI pipeline", Value: ciNone},
+			{Label: "GitHub Actions (.github/workflows/ci.yaml)", Value: ciGitHub},
+			{Label: "GitLab CI (.gitlab-ci.yml)", Value: ciGitLab},
+			{Label: "Makefile targets (ci.mk)", Value: ciMakefile},
+		},
+		InitialValue: &initialValue,
+	})
+
+	showMessage(fmt.Sprintf("✅ CI pipeline: %s\n", provider))
+	if provider == ciNone {
+		return ""
+	}
+	return provider
+}
+
+// generateCI writes the pipeline of the selected CI provider. It runs go vet, go test and go build
+// for webcore and every module of go.work, with the services of the selected libraries running during the tests.
+func generateCI(ctx context.Context, config *Config) error {
+	ciPath := filepath.Join(config.ProjectDir, ciFiles[config.CI])
+	if !confirmOverwrite(ctx, ciPath) {
+		return nil
+	}
+
+	sp := newSpinner()
+	sp.Start(fmt.Sprintf("Generating %s...", ciFiles[config.CI]))
+
+	content, err := ciContent(config)
+	if err != nil {
+		sp.Stop(fmt.Sprintf("❌ Failed to generate %s", ciFiles[config.CI]), 1)
+		return err
+	}
+	if err := validateCIContent(config.CI, content); err != nil {
+		sp.Stop(fmt.Sprintf("❌ Generated %s is invalid", ciFiles[config.CI]), 1)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ciPath), 0755); err != nil {
+		sp.Stop(fmt.Sprintf("❌ Failed to write %s", ciFiles[config.CI]), 1)
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(ciPath), err)
+	}
+	if err := os.WriteFile(ciPath, content, 0644); err != nil {
+		sp.Stop(fmt.Sprintf("❌ Failed to write %s", ciFiles[config.CI]), 1)
+		return fmt.Errorf("failed to write %s: %w", ciFiles[config.CI], err)
+	}
+
+	if config.CI == ciMakefile {
+		if err := includeCIMakefile(config.ProjectDir); err != nil {
+			sp.Stop("❌ Failed to update Makefile", 1)
+			return err
+		}
+	}
+
+	sp.Stop(fmt.Sprintf("✅ %s generated", ciFiles[config.CI]), 0)
+	return nil
+}
+
+// ciContent builds the pipeline of the selected CI provider
+func ciContent(config *Config) ([]byte, error) {
+	dirs, err := ciModuleDirs(config)
+	if err != nil {
+		return nil, err
+	}
+	services, passwords, err := ciServices(config)
+	if err != nil {
+		return nil, err
+	}
+	goVersion, err := projectGoVersion(config.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// In simple mode go.work still lists the removed dummy module, so the modules are built on their own
+	env := map[string]string{}
+	if config.ProjectMode != "mono-repo" {
+		env["GOWORK"] = "off"
+	}
+	for name, password := range passwords {
+		env[name] = password
+	}
+
+	header := "# Generated by webcore-go-install\n"
+	switch config.CI {
+	case ciGitHub:
+		return marshalYAML(header, githubWorkflowFor(config, dirs, services, env))
+	case ciGitLab:
+		variables, err := gitlabServiceVariables(config)
+		if err != nil {
+			return nil, err
+		}
+		return marshalYAML(header, gitlabPipelineFor(dirs, services, variables, env, goVersion))
+	}
+	return []byte(header + ciMakefileFor(dirs, services, env)), nil
+}
+
+// ciModuleDirs returns webcore and, in mono-repo mode, every module of go.work
+func ciModuleDirs(config *Config) ([]string, error) {
+	dirs := []string{"webcore"}
+	if config.ProjectMode != "mono-repo" {
+		return dirs, nil
+	}
+	modules, err := workspaceModules(config.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	return append(dirs, modules...), nil
+}
+
+// ciServices returns the containers of the selected libraries, configured like in docker-compose.yaml.
+// Passwords are replaced by ciPassword, the env overrides of the replaced passwords are returned too.
+func ciServices(config *Config) (map[string]composeService, map[string]string, error) {
+	settings, err := readConfigYAML(config.ProjectDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	services := make(map[string]composeService)
+	passwords := make(map[string]string)
+	backing := selectedBackingServices(config.SelectedLibraries)
+	conns := serviceConnections("ci", "the CI pipeline", settings, backing)
+	for i, service := range backing {
+		conn := conns[i]
+		if conn.Password != "" {
+			conn.Password = ciPassword
+			passwords[settingEnvName(service.Section, "password")] = ciPassword
+		}
+		services[service.Name], _ = newComposeService(service, conn)
+	}
+	return services, passwords, nil
+}
+
+// githubWorkflowFor builds the GitHub Actions workflow. Services that need a command can't be job services
+// and are started with docker run instead.
+func githubWorkflowFor(config *Config, dirs []string, services map[string]composeService, env map[string]string) githubWorkflow {
+	branch := config.GitBranch
+	if branch == "" {
+		branch = defaultGitBranch
+	}
+
+	job := githubJob{RunsOn: "ubuntu-latest", Services: map[string]githubService{}}
+	job.Steps = append(job.Steps,
+		githubStep{Uses: "actions/checkout@v4"},
+		githubStep{Uses: "actions/setup-go@v5", With: map[string]string{"go-version-file": "webcore/go.mod"}},
+	)
+
+	for _, name := range sortedKeys(services) {
+		svc := services[name]
+		if len(svc.Command) > 0 {
+			job.Steps = append(job.Steps, githubStep{Name: "Start " + name, Run: dockerRunCommand(name, svc)})
+			continue
+		}
+		job.Services[name] = githubService{Image: svc.Image, Env: svc.Environment, Ports: svc.Ports, Options: healthOptions(svc.Healthcheck)}
+	}
+
+	for _, dir := range dirs {
+		for _, command := range []string{"vet", "test", "build"} {
+			job.Steps = append(job.Steps, githubStep{
+				Name:             fmt.Sprintf("go %s %s", command, dir),
+				WorkingDirectory: dir,
+				Run:              fmt.Sprintf("go %s ./...", command),
+			})
+		}
+	}
+
+	if len(env) == 0 {
+		env = nil
+	}
+	return githubWorkflow{
+		Name: "CI",
+		On: githubTriggers{
+			Push:        githubBranches{Branches: []string{branch}},
+			PullRequest: githubBranches{Branches: []string{branch}},
+		},
+		Env:  env,
+		Jobs: map[string]githubJob{"test": job},
+	}
+}
+
+// gitlabPipelineFor builds the GitLab CI pipeline. The services are only reachable by their alias,
+// the variables of the job point the connection settings to them.
+func gitlabPipelineFor(dirs []string, services map[string]composeService, variables, env map[string]string, goVersion string) gitlabPipeline {
+	job := gitlabJob{Image: fmt.Sprintf("golang:%s", goVersion)}
+	if len(variables) > 0 {
+		job.Variables = variables
+	}
+	for _, name := range sortedKeys(services) {
+		svc := services[name]
+		serviceEnv := svc.Environment
+		if _, ok := serviceEnv["KAFKA_ADVERTISED_LISTENERS"]; ok {
+			// Clients connect to the broker by its alias, not to a published port
+			serviceEnv = make(map[string]string, len(svc.Environment))
+			for key, value := range svc.Environment {
+				serviceEnv[key] = value
+			}
+			serviceEnv["KAFKA_ADVERTISED_LISTENERS"] = fmt.Sprintf("PLAINTEXT://%s:9092", name)
+		}
+		job.Services = append(job.Services, gitlabService{Name: svc.Image, Alias: name, Command: svc.Command, Variables: serviceEnv})
+	}
+	for _, dir := range dirs {
+		for _, command := range []string{"vet", "test", "build"} {
+			job.Script = append(job.Script, fmt.Sprintf("(cd %s && go %s ./...)", dir, command))
+		}
+	}
+
+	if len(env) == 0 {
+		env = nil
+	}
+	return gitlabPipeline{Variables: env, Test: job}
+}
+
+// gitlabServiceVariables returns the env overrides of the connection settings of the selected libraries
+// with the aliases of the GitLab services, e.g. DATABASE_HOST=postgres, instead of the localhost of config.yaml
+func gitlabServiceVariables(config *Config) (map[string]string, error) {
+	settings, err := readConfigYAML(config.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := make(map[string]string)
+	for _, service := range selectedBackingServices(config.SelectedLibraries) {
+		address := fmt.Sprintf("%s:%d", service.Name, service.Port)
+		if service.Section == "pubsub" {
+			variables["PUBSUB_EMULATOR_HOST"] = address
+			continue
+		}
+
+		section, _ := settings[service.Section].(map[string]any)
+		keys := make([]string, 0)
+		for _, key := range []string{"host", "port", "address", "addr", "brokers"} {
+			if _, ok := section[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			keys = defaultConnectionKeys[service.Section]
+		}
+
+		for _, key := range keys {
+			value := address
+			switch key {
+			case "host":
+				value = service.Name
+			case "port":
+				value = strconv.Itoa(service.Port)
+			}
+			variables[settingEnvName(service.Section, key)] = value
+		}
+	}
+	return variables, nil
+}
+
+// ciMakefileFor builds the ci.mk targets
+func ciMakefileFor(dirs []string, services map[string]composeService, env map[string]string) string {
+	var b strings.Builder
+	b.WriteString("# Included by the Makefile: make ci runs the services, go vet, go test and go build\n\n")
+	for _, key := range sortedKeys(env) {
+		fmt.Fprintf(&b, "export %s = %s\n\n", key, env[key])
+	}
+	fmt.Fprintf(&b, "CI_MODULES := %s\n\n", strings.Join(dirs, " "))
+
+	// The steps run in sequence, the services are removed after the tests also when they fail
+	b.WriteString(".PHONY: ci ci-vet ci-test ci-build ci-services-up ci-services-down\n\n")
+	b.WriteString("ci:\n")
+	b.WriteString("\t$(MAKE) ci-vet\n")
+	b.WriteString("\t$(MAKE) ci-services-up && $(MAKE) ci-test; status=$$?; $(MAKE) ci-services-down; exit $$status\n")
+	b.WriteString("\t$(MAKE) ci-build\n\n")
+	for _, command := range []string{"vet", "test", "build"} {
+		fmt.Fprintf(&b, "ci-%s:\n\tfor dir in $(CI_MODULES); do (cd $$dir && go %s ./...) || exit 1; done\n\n", command, command)
+	}
+
+	b.WriteString("ci-services-up:\n")
+	for _, name := range sortedKeys(services) {
+		b.WriteString("\t" + strings.ReplaceAll(dockerRunCommand(name, services[name]), "$", "$$") + "\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("ci-services-down:\n")
+	for _, name := range sortedKeys(services) {
+		fmt.Fprintf(&b, "\t-docker rm -f webcore-ci-%s\n", name)
+	}
+	return b.String()
+}
+
+// includeCIMakefile includes ci.mk in the Makefile of the project, the Makefile is created if needed
+func includeCIMakefile(projectDir string) error {
+	makefilePath := filepath.Join(projectDir, "Makefile")
+	content, err := os.ReadFile(makefilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read Makefile: %w", err)
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "include ci.mk" {
+			return nil
+		}
+	}
+
+	if len(content) > 0 {
+		if !strings.HasSuffix(string(content), "\n") {
+			content = append(content, '\n')
+		}
+		content = append(content, '\n')
+	}
+	content = append(content, []byte("include ci.mk\n")...)
+	if err := os.WriteFile(makefilePath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write Makefile: %w", err)
+	}
+	return nil
+}
+
+// dockerRunCommand returns the docker run command that starts a service in the background
+func dockerRunCommand(name string, svc composeService) string {
+	args := []string{"docker", "run", "-d", "--rm", "--name", "webcore-ci-" + name}
+	for _, port := range svc.Ports {
+		args = append(args, "-p", port)
+	}
+	for _, key := range sortedKeys(svc.Environment) {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, svc.Environment[key]))
+	}
+	args = append(args, svc.Image)
+	args = append(args, svc.Command...)
+	return strings.Join(args, " ")
+}
+
+// healthOptions converts a compose healthcheck to docker run options
+func healthOptions(check *composeHealthcheck) string {
+	if check == nil || len(check.Test) < 2 {
+		return ""
+	}
+	return fmt.Sprintf("--health-cmd %q --health-interval %s --health-retries %d", strings.Join(check.Test[1:], " "), check.Interval, check.Retries)
+}
+
+// validateCIContent checks the generated pipeline against the required structure of the provider
+func validateCIContent(provider string, content []byte) error {
+	if provider == ciMakefile {
+		return nil
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("failed to parse the generated pipeline: %w", err)
+	}
+
+	switch provider {
+	case ciGitHub:
+		jobs, _ := doc["jobs"].(map[string]any)
+		if doc["on"] == nil || len(jobs) == 0 {
+			return fmt.Errorf("the generated workflow needs on and jobs")
+		}
+		for name, job := range jobs {
+			fields, _ := job.(map[string]any)
+			if fields["runs-on"] == nil || fields["steps"] == nil {
+				return fmt.Errorf("job %s of the generated workflow needs runs-on and steps", name)
+			}
+		}
+	case ciGitLab:
+		job, _ := doc["test"].(map[string]any)
+		if job["script"] == nil {
+			return fmt.Errorf("job test of the generated pipeline needs a script")
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
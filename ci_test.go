@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dlclark/regexp2"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// Published schemas of the CI providers
+const (
+	githubWorkflowSchema = "https://json.schemastore.org/github-workflow.json"
+	gitlabCISchema       = "https://gitlab.com/gitlab-org/gitlab/-/raw/master/app/assets/javascripts/editor/schema/ci.json"
+)
+
+// ciTestLibraries are the libraries of the test project, one of every backing service
+var ciTestLibraries = []LibraryOption{
+	{Name: "database:postgres"},
+	{Name: "redis"},
+	{Name: "kafka:producer"},
+	{Name: "pubsub"},
+}
+
+// newCITestProject creates a mono-repo project with one module and the config.yaml of a local setup
+func newCITestProject(t *testing.T, provider string) *Config {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"webcore/go.mod":         "module github.com/example/orders\n\ngo 1.25.0\n",
+		"modules/orders/go.mod":  "module github.com/example/orders/modules/orders\n\ngo 1.25.0\n",
+		"go.work":                "go 1.25.0\n\nuse (\n\t./webcore\n\t./modules/orders\n)\n",
+		"config.yaml":            "database:\n  host: localhost\n  port: 5432\n  name: app\n  user: postgres\n  password: secret\nredis:\n  address: localhost:6379\nkafka:\n  brokers:\n    - localhost:9092\npubsub:\n  project_id: local\n",
+		"modules/orders/main.go": "package orders\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &Config{ProjectDir: dir, ProjectMode: "mono-repo", CI: provider, GitBranch: "main", SelectedLibraries: ciTestLibraries}
+}
+
+// fetchJSON downloads a JSON document, the test is skipped when it can't be downloaded
+func fetchJSON(t *testing.T, url string) (any, error) {
+	t.Helper()
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Skipf("cannot download %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Skipf("cannot download %s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Skipf("cannot download %s: %v", url, err)
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(body))
+}
+
+// schemaLoader loads the schemas referenced by a published schema over HTTPS
+type schemaLoader struct{ t *testing.T }
+
+func (l schemaLoader) Load(url string) (any, error) {
+	return fetchJSON(l.t, url)
+}
+
+// ecmaRegexp matches the ECMAScript patterns of the published schemas, which Go's regexp doesn't support
+type ecmaRegexp regexp2.Regexp
+
+func (re *ecmaRegexp) MatchString(s string) bool {
+	matched, err := (*regexp2.Regexp)(re).MatchString(s)
+	return err == nil && matched
+}
+
+func (re *ecmaRegexp) String() string {
+	return (*regexp2.Regexp)(re).String()
+}
+
+func compileECMARegexp(pattern string) (jsonschema.Regexp, error) {
+	re, err := regexp2.Compile(pattern, regexp2.ECMAScript)
+	if err != nil {
+		return nil, err
+	}
+	return (*ecmaRegexp)(re), nil
+}
+
+// validateAgainstSchema validates YAML content against a published JSON schema
+func validateAgainstSchema(t *testing.T, schemaURL string, content []byte) {
+	t.Helper()
+	schemaDoc, err := fetchJSON(t, schemaURL)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", schemaURL, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(jsonschema.SchemeURLLoader{"https": schemaLoader{t: t}})
+	compiler.UseRegexpEngine(compileECMARegexp)
+	if err := compiler.AddResource(schemaURL, schemaDoc); err != nil {
+		t.Fatalf("failed to add %s: %v", schemaURL, err)
+	}
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		t.Fatalf("failed to compile %s: %v", schemaURL, err)
+	}
+
+	var doc any
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		t.Fatalf("failed to parse the generated pipeline: %v", err)
+	}
+	// The schema validates JSON values, e.g. float64 numbers
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate(instance); err != nil {
+		t.Errorf("generated pipeline doesn't match %s: %v\n%s", schemaURL, err, content)
+	}
+}
+
+func TestGitHubWorkflowMatchesSchema(t *testing.T) {
+	content, err := ciContent(newCITestProject(t, ciGitHub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateCIContent(ciGitHub, content); err != nil {
+		t.Fatal(err)
+	}
+	validateAgainstSchema(t, githubWorkflowSchema, content)
+}
+
+func TestGitLabPipelineMatchesSchema(t *testing.T) {
+	content, err := ciContent(newCITestProject(t, ciGitLab))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateCIContent(ciGitLab, content); err != nil {
+		t.Fatal(err)
+	}
+	validateAgainstSchema(t, gitlabCISchema, content)
+}
+
+func TestGitLabPipelineUsesServiceAliases(t *testing.T) {
+	content, err := ciContent(newCITestProject(t, ciGitLab))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pipeline gitlabPipeline
+	if err := yaml.Unmarshal(content, &pipeline); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"DATABASE_HOST":        "postgres",
+		"DATABASE_PORT":        "5432",
+		"REDIS_ADDRESS":        "redis:6379",
+		"KAFKA_BROKERS":        "kafka:9092",
+		"PUBSUB_EMULATOR_HOST": "pubsub:8085",
+	}
+	for name, value := range want {
+		if got := pipeline.Test.Variables[name]; got != value {
+			t.Errorf("variable %s = %q, want %q", name, got, value)
+		}
+	}
+	for _, service := range pipeline.Test.Services {
+		if listeners, ok := service.Variables["KAFKA_ADVERTISED_LISTENERS"]; ok && listeners != "PLAINTEXT://kafka:9092" {
+			t.Errorf("KAFKA_ADVERTISED_LISTENERS = %q, want the kafka alias", listeners)
+		}
+	}
+}
+
+func TestCIFilesKeepPasswordsOut(t *testing.T) {
+	for _, provider := range []string{ciGitHub, ciGitLab, ciMakefile} {
+		config := newCITestProject(t, provider)
+		content, err := ciContent(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(content), "secret") {
+			t.Errorf("%s contains the password of config.yaml:\n%s", ciFiles[provider], content)
+		}
+		if !strings.Contains(string(content), "DATABASE_PASSWORD") {
+			t.Errorf("%s doesn't set DATABASE_PASSWORD for the tests:\n%s", ciFiles[provider], content)
+		}
+	}
+}
+
+func TestCIMakefileDryRun(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make is not installed")
+	}
+
+	config := newCITestProject(t, ciMakefile)
+	content, err := ciContent(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(config.ProjectDir, "ci.mk"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := includeCIMakefile(config.ProjectDir); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("make", "-n", "--no-print-directory", "ci")
+	cmd.Dir = config.ProjectDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("make -n ci failed: %v\n%s", err, out)
+	}
+
+	// The services are started before and removed after the tests, then the modules are built
+	order := []string{
+		"go vet ./...",
+		"docker run -d --rm --name webcore-ci-postgres",
+		"go test ./...",
+		"docker rm -f webcore-ci-postgres",
+		"go build ./...",
+	}
+	output := string(out)
+	position := 0
+	for _, want := range order {
+		i := strings.Index(output[position:], want)
+		if i < 0 {
+			t.Fatalf("make -n ci doesn't run %q after the previous steps:\n%s", want, output)
+		}
+		position += i + len(want)
+	}
+	if !strings.Contains(output, "for dir in webcore modules/orders;") {
+		t.Errorf("make -n ci doesn't run the checks in every module:\n%s", output)
+	}
+}
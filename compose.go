@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const composeFileName = "docker-compose.yaml"
+
+// backingService is the container that runs the service of a library locally, declared on its availableLibraries entry
+type backingService struct {
+	Name    string // compose service name
+	Image   string // image without the tag
+	Tag     string // pinned version of the image
+	Port    int    // port of the service inside the container
+	Section string // config.yaml section with the connection settings
+}
+
+// ref returns the image reference with its pinned tag
+func (s backingService) ref() string {
+	return s.Image + ":" + s.Tag
+}
+
+// composeFile is the generated docker-compose.yaml
+type composeFile struct {
+	Services map[string]composeService    `yaml:"services"`
+	Volumes  map[string]map[string]string `yaml:"volumes,omitempty"`
+}
+
+// composeService is a service of docker-compose.yaml
+type composeService struct {
+	Image       string              `yaml:"image"`
+	Command     []string            `yaml:"command,omitempty"`
+	EnvFile     []string            `yaml:"env_file,omitempty"`
+	Environment map[string]string   `yaml:"environment,omitempty"`
+	Ports       []string            `yaml:"ports,omitempty"`
+	Volumes     []string            `yaml:"volumes,omitempty"`
+	Healthcheck *composeHealthcheck `yaml:"healthcheck,omitempty"`
+}
+
+// composeHealthcheck is the healthcheck of a compose service
+type composeHealthcheck struct {
+	Test     []string `yaml:"test"`
+	Interval string   `yaml:"interval"`
+	Retries  int      `yaml:"retries"`
+}
+
+// connectionSettings are the connection settings of a config.yaml section
+type connectionSettings struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+}
+
+// selectedBackingServices returns the backing services of the selected libraries, each service once
+// The services are looked up in the catalog, as the libraries of a checkpoint don't carry them.
+func selectedBackingServices(libraries []LibraryOption) []backingService {
+	selected := make(map[string]bool)
+	for _, lib := range libraries {
+		selected[lib.Name] = true
+	}
+
+	services := make([]backingService, 0)
+	seen := make(map[string]bool)
+	for _, lib := range availableLibraries {
+		if !selected[lib.Name] || lib.Service == nil || seen[lib.Service.Name] {
+			continue
+		}
+		seen[lib.Service.Name] = true
+		services = append(services, *lib.Service)
+	}
+	return services
+}
+
+// generateCompose writes a docker-compose.yaml with a container for every selected library that needs a service.
+// The published ports and credentials are taken from config.yaml, so the project runs against the containers.
+// Passwords are referenced as ${NAME} from .env instead of copied, the ones only in config.yaml are added to .env.
+func generateCompose(ctx context.Context, config *Config) error {
+	services := selectedBackingServices(config.SelectedLibraries)
+	if len(services) == 0 {
+		showMessage(fmt.Sprintf("⏭️ No library needs a service, %s not generated", composeFileName))
+		return nil
+	}
+
+	composePath := filepath.Join(config.ProjectDir, composeFileName)
+	if !confirmOverwrite(ctx, composePath) {
+		return nil
+	}
+
+	settings, err := readConfigYAML(config.ProjectDir)
+	if err != nil {
+		return err
+	}
+	// Secrets moved to .env by the service settings are referenced, docker compose reads them from .env
+	if settings == nil {
+		settings = map[string]any{}
+	}
+	envSections, err := applyEnvFileReferences(config.ProjectDir, settings)
+	if err != nil {
+		return err
+	}
+
+	sp := newSpinner()
+	sp.Start(fmt.Sprintf("Generating %s...", composeFileName))
+
+	compose := composeFile{Services: map[string]composeService{}, Volumes: map[string]map[string]string{}}
+	conns := serviceConnections("compose", composeFileName, settings, services)
+	envValues := make(map[string]string)
+	for i, service := range services {
+		conn := conns[i]
+		if !isLocalHost(conn.Host) {
+			warn("compose", fmt.Sprintf("config.yaml %s host is %s, update it to localhost to use the %s container", service.Section, conn.Host, service.Name))
+		}
+		// docker-compose.yaml is committed, the password stays in the ignored .env
+		if conn.Password != "" && !strings.HasPrefix(conn.Password, "${") {
+			name := settingEnvName(service.Section, "password")
+			envValues[name] = conn.Password
+			conn.Password = "${" + name + "}"
+			envSections[service.Section] = true
+		}
+		composeSvc, volume := newComposeService(service, conn)
+		if envSections[service.Section] {
+			composeSvc.EnvFile = []string{envFileName}
+		}
+		compose.Services[service.Name] = composeSvc
+		if volume != "" {
+			compose.Volumes[volume] = map[string]string{}
+		}
+	}
+
+	if len(envValues) > 0 {
+		if err := writeEnvFile(filepath.Join(config.ProjectDir, envFileName), envValues); err != nil {
+			sp.Stop(fmt.Sprintf("❌ Failed to write %s", envFileName), 1)
+			return err
+		}
+	}
+
+	header := "# Generated by webcore-go-install for the selected libraries, start the services with: docker compose up -d\n"
+	content, err := marshalYAML(header, compose)
+	if err != nil {
+		sp.Stop(fmt.Sprintf("❌ Failed to generate %s", composeFileName), 1)
+		return fmt.Errorf("failed to generate %s: %w", composeFileName, err)
+	}
+	if err := os.WriteFile(composePath, content, 0644); err != nil {
+		sp.Stop(fmt.Sprintf("❌ Failed to write %s", composeFileName), 1)
+		return fmt.Errorf("failed to write %s: %w", composeFileName, err)
+	}
+
+	sp.Stop(fmt.Sprintf("✅ %s generated", composeFileName), 0)
+	return nil
+}
+
+// newComposeService returns the compose service of a backing service and the name of its data volume, if any
+func newComposeService(service backingService, conn connectionSettings) (composeService, string) {
+	svc := composeService{
+		Image: service.ref(),
+		Ports: []string{fmt.Sprintf("%d:%d", conn.Port, service.Port)},
+	}
+
+	volume := ""
+	switch service.Name {
+	case "postgres":
+		volume = "postgres-data"
+		svc.Environment = map[string]string{"POSTGRES_USER": conn.User, "POSTGRES_PASSWORD": conn.Password, "POSTGRES_DB": conn.Name}
+		svc.Volumes = []string{volume + ":/var/lib/postgresql/data"}
+		svc.Healthcheck = &composeHealthcheck{Test: []string{"CMD", "pg_isready", "-U", conn.User}, Interval: "5s", Retries: 10}
+	case "mysql":
+		volume = "mysql-data"
+		svc.Environment = map[string]string{"MYSQL_ROOT_PASSWORD": conn.Password, "MYSQL_DATABASE": conn.Name}
+		if conn.User != "root" {
+			svc.Environment["MYSQL_USER"] = conn.User
+			svc.Environment["MYSQL_PASSWORD"] = conn.Password
+		}
+		svc.Volumes = []string{volume + ":/var/lib/mysql"}
+		svc.Healthcheck = &composeHealthcheck{Test: []string{"CMD", "mysqladmin", "ping", "-h", "localhost"}, Interval: "5s", Retries: 10}
+	case "mongo":
+		volume = "mongo-data"
+		svc.Environment = map[string]string{"MONGO_INITDB_ROOT_USERNAME": conn.User, "MONGO_INITDB_ROOT_PASSWORD": conn.Password, "MONGO_INITDB_DATABASE": conn.Name}
+		svc.Volumes = []string{volume + ":/data/db"}
+	case "redis":
+		if conn.Password != "" {
+			svc.Command = []string{"redis-server", "--requirepass", conn.Password}
+		}
+		svc.Healthcheck = &composeHealthcheck{Test: []string{"CMD", "redis-cli", "ping"}, Interval: "5s", Retries: 10}
+	case "kafka":
+		// Single node in KRaft mode, the advertised listener is the published port
+		svc.Environment = map[string]string{
+			"KAFKA_NODE_ID":                                  "1",
+			"KAFKA_PROCESS_ROLES":                            "broker,controller",
+			"KAFKA_LISTENERS":                                "PLAINTEXT://:9092,CONTROLLER://:9093",
+			"KAFKA_ADVERTISED_LISTENERS":                     fmt.Sprintf("PLAINTEXT://localhost:%d", conn.Port),
+			"KAFKA_CONTROLLER_LISTENER_NAMES":                "CONTROLLER",
+			"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP":           "CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT",
+			"KAFKA_CONTROLLER_QUORUM_VOTERS":                 "1@localhost:9093",
+			"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR":         "1",
+			"KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR": "1",
+			"KAFKA_TRANSACTION_STATE_LOG_MIN_ISR":            "1",
+			"KAFKA_GROUP_INITIAL_REBALANCE_DELAY_MS":         "0",
+		}
+	case "pubsub":
+		// The application connects to the emulator through PUBSUB_EMULATOR_HOST=localhost:<port>
+		svc.Command = []string{"gcloud", "beta", "emulators", "pubsub", "start", "--project=" + conn.Name, fmt.Sprintf("--host-port=0.0.0.0:%d", service.Port)}
+	}
+	return svc, volume
+}
+
+// marshalYAML encodes v as YAML with two space indentation after a header comment
+func marshalYAML(header string, v any) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(header)
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// readConfigYAML reads the enabled settings of config.yaml in the project directory, nil when it doesn't exist
+func readConfigYAML(projectDir string) (map[string]any, error) {
+	return readSettingsFile(filepath.Join(projectDir, "config.yaml"))
+}
+
+// readSettingsFile reads the enabled settings of a config file, nil when it doesn't exist
+func readSettingsFile(configPath string) (map[string]any, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(configPath), err)
+	}
+
+	settings := map[string]any{}
+	if err := yaml.Unmarshal(content, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(configPath), err)
+	}
+	return settings, nil
+}
+
+// serviceConnections returns the connection settings of the services. config.yaml has one section per kind of
+// service, a service that shares its section with a service before it (mysql next to postgres in database) is
+// published on its own port, so the containers don't conflict. Both are warned about on step, for the file
+// the services are generated in.
+func serviceConnections(step, file string, settings map[string]any, services []backingService) []connectionSettings {
+	conns := make([]connectionSettings, len(services))
+	owners := make(map[string]string)
+	published := make(map[int]string)
+	for i, service := range services {
+		conn := connectionSettingsOf(settings, service)
+		if owner, shared := owners[service.Section]; shared {
+			conn.Port = service.Port
+			warn(step, fmt.Sprintf("%s and %s both use the config.yaml %s section, %s is published on its own port %d in %s", owner, service.Name, service.Section, service.Name, conn.Port, file))
+		} else {
+			owners[service.Section] = service.Name
+		}
+		if other, taken := published[conn.Port]; taken {
+			warn(step, fmt.Sprintf("%s and %s are both published on port %d in %s, change the port of one of them in config.yaml", other, service.Name, conn.Port, file))
+		}
+		published[conn.Port] = service.Name
+		conns[i] = conn
+	}
+	return conns
+}
+
+// connectionSettingsOf reads the connection settings of a backing service from its config.yaml section.
+// Settings that aren't set fall back to the defaults of the container.
+func connectionSettingsOf(settings map[string]any, service backingService) connectionSettings {
+	section, _ := settings[service.Section].(map[string]any)
+	conn := connectionSettings{Host: "localhost", Port: service.Port}
+	switch service.Section {
+	case "database":
+		conn.User, conn.Password, conn.Name = "webcore", "webcore", "webcore"
+	case "pubsub":
+		conn.Name = "local-project"
+	}
+
+	// Address style settings: redis address, kafka brokers and the pubsub emulator host
+	for _, key := range []string{"address", "addr", "brokers", "emulator_host"} {
+		if value := firstSetting(section, key); value != "" {
+			host, port, err := net.SplitHostPort(strings.TrimSpace(strings.Split(value, ",")[0]))
+			if err == nil {
+				conn.Host = host
+				if p, err := strconv.Atoi(port); err == nil {
+					conn.Port = p
+				}
+			}
+			break
+		}
+	}
+
+	if value := firstSetting(section, "host"); value != "" {
+		conn.Host = value
+	}
+	if value := firstSetting(section, "port"); value != "" {
+		if p, err := strconv.Atoi(value); err == nil {
+			conn.Port = p
+		}
+	}
+	if value := firstSetting(section, "user", "username"); value != "" {
+		conn.User = value
+	}
+	if value := firstSetting(section, "password"); value != "" {
+		conn.Password = value
+	}
+	if value := firstSetting(section, "name", "dbname", "database", "project_id", "project"); value != "" {
+		conn.Name = value
+	}
+	return conn
+}
+
+// firstSetting returns the first of keys that is set in section, lists are joined with commas
+func firstSetting(section map[string]any, keys ...string) string {
+	for _, key := range keys {
+		switch value := section[key].(type) {
+		case nil:
+			continue
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			return strings.Join(items, ",")
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// isLocalHost checks if host reaches the published ports of the containers
+func isLocalHost(host string) bool {
+	switch host {
+	case "", "localhost", "127.0.0.1", "::1", "0.0.0.0":
+		return true
+	}
+	return false
+}
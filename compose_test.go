@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServiceConnections(t *testing.T) {
+	postgres := *availableLibraries[0].Service
+	mysql := *availableLibraries[1].Service
+	redis := backingService{Name: "redis", Port: 6379, Section: "redis"}
+
+	tests := []struct {
+		name     string
+		settings map[string]any
+		services []backingService
+		ports    []int
+		warnings []string
+	}{
+		{
+			name:     "port from config.yaml",
+			settings: map[string]any{"database": map[string]any{"port": 5433}},
+			services: []backingService{postgres, redis},
+			ports:    []int{5433, 6379},
+		},
+		{
+			name:     "shared section gets its own port",
+			settings: map[string]any{"database": map[string]any{"port": 5433}},
+			services: []backingService{postgres, mysql},
+			ports:    []int{5433, 3306},
+			warnings: []string{"postgres and mysql both use the config.yaml database section"},
+		},
+		{
+			name:     "same published port",
+			settings: map[string]any{"database": map[string]any{"port": 6379}},
+			services: []backingService{postgres, redis},
+			ports:    []int{6379, 6379},
+			warnings: []string{"postgres and redis are both published on port 6379"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings = nil
+			t.Cleanup(func() { warnings = nil })
+
+			conns := serviceConnections("compose", composeFileName, tt.settings, tt.services)
+			for i, conn := range conns {
+				if conn.Port != tt.ports[i] {
+					t.Errorf("%s port = %d, want %d", tt.services[i].Name, conn.Port, tt.ports[i])
+				}
+			}
+			if len(warnings) != len(tt.warnings) {
+				t.Fatalf("warnings = %+v, want %d", warnings, len(tt.warnings))
+			}
+			for i, want := range tt.warnings {
+				if !strings.Contains(warnings[i].Message, want) {
+					t.Errorf("warning %q doesn't contain %q", warnings[i].Message, want)
+				}
+			}
+		})
+	}
+}
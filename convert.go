@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// runConvertCommand handles the "convert" subcommand
+func runConvertCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	to := flags.String("to", "", "target project mode: mono-repo or simple")
+	folder := flags.String("folder", "", "module folder name under modules/ (mono-repo only)")
+	pkgName := flags.String("package", "", "Go package name of the new module (mono-repo only, default: derived from the folder name)")
+	moduleModName := flags.String("module", "", "Go module name of the new module (mono-repo only, default: derived from the project module name)")
+	dir := flags.String("dir", "", "project directory (default: the project containing the current directory)")
+	skipVerify := flags.Bool("skip-verify", false, "don't run go build after converting")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	projectDir, err := resolveProjectDir(*dir)
+	if err != nil {
+		return err
+	}
+
+	switch *to {
+	case "mono-repo":
+		if !isValidFolderName(*folder) {
+			return invalidInputError("", fmt.Errorf("usage: webcore-go-install convert --to mono-repo --folder <name> (lowercase letters, numbers and hyphens)"))
+		}
+		showIntro(fmt.Sprintf("Converting project to mono-repo with module %s", *folder))
+		err = convertToMonoRepo(ctx, projectDir, *folder, *pkgName, *moduleModName)
+	case "simple":
+		showIntro("Converting project to simple mode")
+		err = convertToSimple(ctx, projectDir)
+	default:
+		return invalidInputError("", fmt.Errorf("usage: webcore-go-install convert --to <mono-repo|simple> [--folder <name>]"))
+	}
+	if err != nil {
+		return err
+	}
+
+	if !*skipVerify {
+		if err := verifyBuild(ctx, projectDir); err != nil {
+			return err
+		}
+	}
+
+	showOutro(fmt.Sprintf("✅ Project converted to %s mode", *to))
+	return nil
+}
+
+// convertToMonoRepo moves webcore/app into modules/<folder> with its own go.mod
+func convertToMonoRepo(ctx context.Context, projectDir, folder, pkgName, moduleModName string) error {
+	appPath := filepath.Join(projectDir, "webcore", "app")
+	newPath := filepath.Join(projectDir, "modules", folder)
+	webcoreGoModPath := filepath.Join(projectDir, "webcore", "go.mod")
+
+	if _, err := os.Stat(appPath); err != nil {
+		return fmt.Errorf("webcore/app not found, is this a simple mode project? %w", err)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("modules/%s already exists", folder)
+	}
+
+	webcoreGoMod, err := readGoMod(webcoreGoModPath)
+	if err != nil {
+		return err
+	}
+	moduleName := webcoreGoMod.Module.Mod.Path
+	if moduleModName == "" {
+		moduleModName = defaultModuleModName(moduleName, folder)
+	}
+	if err := validateModuleName(moduleModName); err != nil {
+		return invalidInputError("set a valid module path with --module", fmt.Errorf("invalid module name %q: %w", moduleModName, err))
+	}
+	if pkgName == "" {
+		pkgName = derivePackageName(folder)
+	}
+	if err := validatePackageName(pkgName); err != nil {
+		return invalidInputError("set a valid package name with --package", fmt.Errorf("invalid package name %q: %w", pkgName, err))
+	}
+
+	// Move webcore/app to modules/<folder>
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return fmt.Errorf("failed to create modules directory: %w", err)
+	}
+	if err := os.Rename(appPath, newPath); err != nil {
+		return fmt.Errorf("failed to move webcore/app: %w", err)
+	}
+	showMessage(fmt.Sprintf("✅ Moved webcore/app to modules/%s\n", folder))
+
+	// Create go.mod of the new module with the requirements of webcore
+	goMod := &modfile.File{Syntax: &modfile.FileSyntax{}}
+	if err := goMod.AddModuleStmt(moduleModName); err != nil {
+		return err
+	}
+	if webcoreGoMod.Go != nil {
+		if err := goMod.AddGoStmt(webcoreGoMod.Go.Version); err != nil {
+			return err
+		}
+	}
+	for _, req := range webcoreGoMod.Require {
+		goMod.AddNewRequire(req.Mod.Path, req.Mod.Version, req.Indirect)
+	}
+	goMod.Cleanup()
+	if err := os.WriteFile(filepath.Join(newPath, "go.mod"), modfile.Format(goMod.Syntax), 0644); err != nil {
+		return fmt.Errorf("failed to write module go.mod: %w", err)
+	}
+	showMessage(fmt.Sprintf("✅ Created modules/%s/go.mod with module %s\n", folder, moduleModName))
+
+	// Replace package name and import paths in all Go files
+	if err := replacePackageNames(newPath, "app", pkgName, moduleName+"/app", moduleModName); err != nil {
+		return fmt.Errorf("failed to update package names: %w", err)
+	}
+
+	if err := replacePackagesGoModule(projectDir, moduleName+"/app", "app", moduleModName, pkgName); err != nil {
+		return fmt.Errorf("failed to update packages.go: %w", err)
+	}
+
+	// Require the new module from webcore/go.mod through a local replace, so webcore also builds without go.work
+	if err := requireLocalModule(webcoreGoMod, moduleModName, "../modules/"+folder); err != nil {
+		return fmt.Errorf("failed to update webcore/go.mod: %w", err)
+	}
+	if err := os.WriteFile(webcoreGoModPath, modfile.Format(webcoreGoMod.Syntax), 0644); err != nil {
+		return fmt.Errorf("failed to update webcore/go.mod: %w", err)
+	}
+	showMessage(fmt.Sprintf("✅ Required %s from webcore/go.mod with a replace to ../modules/%s\n", moduleModName, folder))
+
+	goVersion := ""
+	if webcoreGoMod.Go != nil {
+		goVersion = webcoreGoMod.Go.Version
+	}
+	if err := updateGoWorkUse(projectDir, goVersion, "./modules/"+folder, true); err != nil {
+		return fmt.Errorf("failed to update go.work: %w", err)
+	}
+
+	if err := updateLockMode(projectDir, "mono-repo", folder, pkgName, moduleModName); err != nil {
+		return err
+	}
+
+	syncGoWork(ctx, projectDir)
+	return nil
+}
+
+// convertToSimple collapses the single module of a mono-repo project back into webcore/app
+func convertToSimple(ctx context.Context, projectDir string) error {
+	appPath := filepath.Join(projectDir, "webcore", "app")
+	webcoreGoModPath := filepath.Join(projectDir, "webcore", "go.mod")
+
+	if _, err := os.Stat(appPath); err == nil {
+		return fmt.Errorf("webcore/app already exists")
+	}
+
+	goMods, _ := filepath.Glob(filepath.Join(projectDir, "modules", "*", "go.mod"))
+	if len(goMods) != 1 {
+		return fmt.Errorf("only a mono-repo with exactly one module can be converted, found %d modules", len(goMods))
+	}
+	modulePath := filepath.Dir(goMods[0])
+	folder := filepath.Base(modulePath)
+
+	moduleGoMod, err := readGoMod(goMods[0])
+	if err != nil {
+		return err
+	}
+	moduleModName := moduleGoMod.Module.Mod.Path
+
+	webcoreGoMod, err := readGoMod(webcoreGoModPath)
+	if err != nil {
+		return err
+	}
+	moduleName := webcoreGoMod.Module.Mod.Path
+
+	pkgName, err := packageNameOf(modulePath)
+	if err != nil {
+		return err
+	}
+
+	// Move contents except go.mod and go.sum into webcore/app
+	if err := os.MkdirAll(appPath, 0755); err != nil {
+		return fmt.Errorf("failed to create app directory: %w", err)
+	}
+	entries, err := os.ReadDir(modulePath)
+	if err != nil {
+		return fmt.Errorf("failed to read modules/%s: %w", folder, err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == "go.mod" || entry.Name() == "go.sum" {
+			continue
+		}
+		if err := os.Rename(filepath.Join(modulePath, entry.Name()), filepath.Join(appPath, entry.Name())); err != nil {
+			return fmt.Errorf("failed to move %s: %w", entry.Name(), err)
+		}
+	}
+	showMessage(fmt.Sprintf("✅ Moved modules/%s to webcore/app\n", folder))
+
+	// Drop the requirement and replacement of the removed module from webcore/go.mod
+	for _, req := range append([]*modfile.Require(nil), webcoreGoMod.Require...) {
+		if _, ok := renamePath(req.Mod.Path, moduleModName, moduleModName); ok {
+			if err := webcoreGoMod.DropRequire(req.Mod.Path); err != nil {
+				return fmt.Errorf("failed to update webcore/go.mod: %w", err)
+			}
+		}
+	}
+	for _, rep := range append([]*modfile.Replace(nil), webcoreGoMod.Replace...) {
+		if _, ok := renamePath(rep.Old.Path, moduleModName, moduleModName); ok {
+			if err := webcoreGoMod.DropReplace(rep.Old.Path, rep.Old.Version); err != nil {
+				return fmt.Errorf("failed to update webcore/go.mod: %w", err)
+			}
+		}
+	}
+
+	// Merge the module requirements into webcore/go.mod
+	have := make(map[string]bool)
+	for _, req := range webcoreGoMod.Require {
+		have[req.Mod.Path] = true
+	}
+	for _, req := range moduleGoMod.Require {
+		if _, self := renamePath(req.Mod.Path, moduleName, moduleName); !have[req.Mod.Path] && !self {
+			webcoreGoMod.AddNewRequire(req.Mod.Path, req.Mod.Version, req.Indirect)
+		}
+	}
+	webcoreGoMod.Cleanup()
+	if err := os.WriteFile(webcoreGoModPath, modfile.Format(webcoreGoMod.Syntax), 0644); err != nil {
+		return fmt.Errorf("failed to update webcore/go.mod: %w", err)
+	}
+
+	if err := replacePackageNames(appPath, pkgName, "app", moduleModName, moduleName+"/app"); err != nil {
+		return fmt.Errorf("failed to update package names: %w", err)
+	}
+
+	if err := replacePackagesGoModule(projectDir, moduleModName, pkgName, moduleName+"/app", "app"); err != nil {
+		return fmt.Errorf("failed to update packages.go: %w", err)
+	}
+
+	if err := os.RemoveAll(modulePath); err != nil {
+		return fmt.Errorf("failed to remove modules/%s: %w", folder, err)
+	}
+	showMessage(fmt.Sprintf("✅ Removed modules/%s folder", folder))
+
+	if err := updateGoWorkUse(projectDir, "", "./modules/"+folder, false); err != nil {
+		return fmt.Errorf("failed to update go.work: %w", err)
+	}
+
+	return updateLockMode(projectDir, "simple", "", "", "")
+}
+
+// localModuleVersion is the version go mod tidy gives a module that is only available through a replace
+const localModuleVersion = "v0.0.0-00010101000000-000000000000"
+
+// requireLocalModule adds a requirement of modulePath and a replace with its directory to goMod,
+// convertToSimple drops both again
+func requireLocalModule(goMod *modfile.File, modulePath, dir string) error {
+	if err := goMod.AddRequire(modulePath, localModuleVersion); err != nil {
+		return err
+	}
+	if err := goMod.AddReplace(modulePath, "", dir, ""); err != nil {
+		return err
+	}
+	goMod.Cleanup()
+	return nil
+}
+
+// readGoMod reads and parses a go.mod file
+func readGoMod(goModPath string) (*modfile.File, error) {
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := modfile.Parse(goModPath, content, nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.Module == nil {
+		return nil, fmt.Errorf("%s has no module directive", goModPath)
+	}
+	return f, nil
+}
+
+// packageNameOf returns the package name declared by the Go files in dir
+func packageNameOf(dir string) (string, error) {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return "", err
+		}
+		return f.Name.Name, nil
+	}
+	return "", fmt.Errorf("no Go files found in %s", dir)
+}
+
+// replacePackagesGoModule replaces the import and NewModule call of a module in webcore/deps/packages.go
+func replacePackagesGoModule(projectDir, oldImportPath, oldAlias, newImportPath, newAlias string) error {
+	showMessage("📝 Updating webcore/deps/packages.go...")
+
+	packagesPath := filepath.Join(projectDir, "webcore", "deps", "packages.go")
+	content, err := os.ReadFile(packagesPath)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.Contains(line, fmt.Sprintf("\"%s\"", oldImportPath)):
+			lines[i] = fmt.Sprintf("\t%s \"%s\"", newAlias, newImportPath)
+		case strings.Contains(line, oldAlias+".NewModule()"):
+			lines[i] = strings.Replace(line, oldAlias+".NewModule()", newAlias+".NewModule()", 1)
+		}
+	}
+
+	if err := os.WriteFile(packagesPath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		return err
+	}
+
+	showMessage("✅ Updated webcore/deps/packages.go")
+	return nil
+}
+
+// updateGoWorkUse adds or removes a use directive in go.work, creating the file if needed
+func updateGoWorkUse(projectDir, goVersion, dir string, add bool) error {
+	goWorkPath := filepath.Join(projectDir, "go.work")
+
+	content, err := os.ReadFile(goWorkPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if os.IsNotExist(err) && !add {
+		return nil
+	}
+
+	f, err := modfile.ParseWork(goWorkPath, content, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(content) == 0 {
+		if goVersion != "" {
+			if err := f.AddGoStmt(goVersion); err != nil {
+				return err
+			}
+		}
+		if err := f.AddUse("./webcore", ""); err != nil {
+			return err
+		}
+	}
+
+	if add {
+		if err := f.AddUse(dir, ""); err != nil {
+			return err
+		}
+	} else {
+		if err := f.DropUse(dir); err != nil {
+			return err
+		}
+	}
+
+	f.Cleanup()
+	if err := os.WriteFile(goWorkPath, modfile.Format(f.Syntax), 0644); err != nil {
+		return err
+	}
+
+	showMessage(fmt.Sprintf("✅ Updated go.work (%s)", dir))
+	return nil
+}
+
+// updateLockMode records the new project mode in the lock file, if there is one
+func updateLockMode(projectDir, mode, folder, pkgName, moduleModName string) error {
+	lockPath := filepath.Join(projectDir, lockFileName)
+	lock, err := readLockFile(lockPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	lock.Config.ProjectMode = mode
+	lock.Config.FolderName = folder
+	lock.Config.PackageName = pkgName
+	lock.Config.ModuleModName = moduleModName
+
+	return saveLockFile(lockPath, lock)
+}
+
+// syncGoWork runs go work sync, failures are reported as warnings
+func syncGoWork(ctx context.Context, projectDir string) {
+	sp := newSpinner()
+	sp.Start("Running go work sync...")
+
+	cmd := exec.CommandContext(ctx, "go", "work", "sync")
+	cmd.Dir = projectDir
+
+	if err := runExternal(ctx, cmd); err != nil {
+		sp.Stop("⚠️ go work sync completed with warnings", 0)
+		warn("", fmt.Sprintf("go work sync failed: %v", err))
+		return
+	}
+
+	sp.Stop("✅ go.work synced", 0)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+)
+
+func TestRequireLocalModule(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "no requirements",
+			content: "module github.com/acme/orders\n\ngo 1.25.0\n",
+			want: "module github.com/acme/orders\n\ngo 1.25.0\n\n" +
+				"require github.com/acme/orders/modules/billing v0.0.0-00010101000000-000000000000\n\n" +
+				"replace github.com/acme/orders/modules/billing => ../modules/billing\n",
+		},
+		{
+			name:    "existing requirements",
+			content: "module github.com/acme/orders\n\ngo 1.25.0\n\nrequire golang.org/x/mod v0.29.0\n",
+			want: "module github.com/acme/orders\n\ngo 1.25.0\n\n" +
+				"require (\n\tgolang.org/x/mod v0.29.0\n\tgithub.com/acme/orders/modules/billing v0.0.0-00010101000000-000000000000\n)\n\n" +
+				"replace github.com/acme/orders/modules/billing => ../modules/billing\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goMod, err := modfile.Parse("go.mod", []byte(tt.content), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := requireLocalModule(goMod, "github.com/acme/orders/modules/billing", "../modules/billing"); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(modfile.Format(goMod.Syntax)); got != tt.want {
+				t.Errorf("go.mod = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Converting to a mono-repo and back restores the files of the simple project
+func TestConvertRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.work":                  "go 1.25.0\n\nuse ./webcore\n",
+		"webcore/go.mod":           "module github.com/acme/orders\n\ngo 1.25.0\n",
+		"webcore/main.go":          "package main\n\nimport _ \"github.com/acme/orders/deps\"\n\nfunc main() {}\n",
+		"webcore/deps/packages.go": "package deps\n\nimport (\n\tapp \"github.com/acme/orders/app\"\n)\n\nvar Modules = []any{app.NewModule()}\n",
+		"webcore/app/module.go":    "package app\n\nconst (\n\tModuleName    = \"app\"\n)\n\nfunc NewModule() any { return ModuleName }\n",
+	}
+	for name, content := range files {
+		writeTestFile(t, dir, name, content)
+	}
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOPROXY", "off")
+
+	ctx := context.Background()
+	if err := convertToMonoRepo(ctx, dir, "billing", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	moduleGoMod, err := os.ReadFile(filepath.Join(dir, "modules/billing/go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(moduleGoMod), "module github.com/acme/orders/modules/billing\n") {
+		t.Errorf("modules/billing/go.mod = %q", moduleGoMod)
+	}
+	webcoreGoMod, err := os.ReadFile(filepath.Join(dir, "webcore/go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"require github.com/acme/orders/modules/billing ", "replace github.com/acme/orders/modules/billing => ../modules/billing"} {
+		if !strings.Contains(string(webcoreGoMod), want) {
+			t.Errorf("webcore/go.mod = %q, want %q in it", webcoreGoMod, want)
+		}
+	}
+
+	if err := convertToSimple(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range files {
+		if name == "go.work" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != want {
+			t.Errorf("%s = %q after the round trip, want %q", name, content, want)
+		}
+	}
+	if fileExists(filepath.Join(dir, "modules", "billing")) {
+		t.Error("modules/billing still exists")
+	}
+}
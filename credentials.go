@@ -0,0 +1,258 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yarlson/tap"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// credentialsFileName holds the plaintext of the hashed passwords when they can't be shown, mode 0600 and ignored by git
+const credentialsFileName = ".credentials"
+
+// credential is a secret generated for access.yaml, its plaintext is only shown once at the end of the run
+type credential struct {
+	Kind   string // "API key" or "password"
+	Name   string // owner of the secret, e.g. the client or user name
+	Secret string
+	Hashed bool // whether access.yaml only holds the bcrypt hash
+}
+
+// generatedCredentials are the credentials generated by this run, they are never written to the checkpoint or report
+var generatedCredentials []credential
+
+// credentialField is an access.yaml key holding a credential, or a list of them
+type credentialField struct {
+	Kind   string // "API key" or "password"
+	Hashed bool   // whether the authstore of the library compares bcrypt hashes
+}
+
+// credentialFields are the access.yaml keys with credentials. The YAML authstore of authentication:basic
+// compares bcrypt hashes, the API keys of authentication:apikey are compared as they are.
+var credentialFields = map[string]credentialField{
+	"key":           {Kind: "API key"},
+	"keys":          {Kind: "API key"},
+	"apikey":        {Kind: "API key"},
+	"apikeys":       {Kind: "API key"},
+	"api_key":       {Kind: "API key"},
+	"api_keys":      {Kind: "API key"},
+	"password":      {Kind: "password", Hashed: true},
+	"password_hash": {Kind: "password", Hashed: true},
+}
+
+// ownerFields are the keys next to a secret that name its owner
+var ownerFields = []string{"name", "username", "user", "client", "client_id", "id"}
+
+// credentialWriter replaces the credentials of access.yaml in place, so its comments and layout are kept
+type credentialWriter struct {
+	apiKeys, passwords bool
+	lines              []string
+	edits              []lineEdit
+	reencode           bool // whether a value can't be replaced in place
+	credentials        []credential
+}
+
+// writeAccessFile creates access.yaml from the example with mode 0600.
+// With authentication:apikey or authentication:basic the example API keys and passwords are replaced by fresh ones.
+func writeAccessFile(src, dst string, libraries []LibraryOption) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	apiKeys := hasLibraryName(libraries, "authentication:apikey")
+	passwords := hasLibraryName(libraries, "authentication:basic")
+	if apiKeys || passwords {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", src, err)
+		}
+
+		w := &credentialWriter{apiKeys: apiKeys, passwords: passwords, lines: strings.Split(string(content), "\n")}
+		if err := w.replace(&doc, ""); err != nil {
+			return err
+		}
+		if len(w.credentials) == 0 {
+			warn("config-files", "No API keys or passwords found in access.yaml.example, add the credentials to access.yaml yourself")
+		}
+
+		content = []byte(applyLineEdits(w.lines, w.edits))
+		if w.reencode {
+			warn("config-files", "access.yaml has credentials that can't be replaced in place, the file was rewritten and may have lost comments and formatting")
+			if content, err = marshalYAML("", &doc); err != nil {
+				return fmt.Errorf("failed to encode access.yaml: %w", err)
+			}
+		}
+		generatedCredentials = append(generatedCredentials, w.credentials...)
+	}
+
+	if err := os.WriteFile(dst, content, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(dst, 0600)
+}
+
+// replace replaces the API keys and passwords below node with random ones and collects their plaintext
+func (w *credentialWriter) replace(node *yaml.Node, path string) error {
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			if err := w.replace(child, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	owner := path
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key, value := node.Content[i].Value, node.Content[i+1]; value.Kind == yaml.ScalarNode && value.Value != "" && contains(ownerFields, strings.ToLower(key)) {
+			owner = value.Value
+			break
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		field, ok := credentialFields[strings.ToLower(node.Content[i].Value)]
+		enabled := ok && ((field.Kind == "API key" && w.apiKeys) || (field.Kind == "password" && w.passwords))
+		switch {
+		case enabled && value.Kind == yaml.ScalarNode:
+			if err := w.set(value, owner, field); err != nil {
+				return err
+			}
+		case enabled && value.Kind == yaml.SequenceNode && isScalarList(value):
+			for _, item := range value.Content {
+				if err := w.set(item, owner, field); err != nil {
+					return err
+				}
+			}
+		default:
+			childPath := node.Content[i].Value
+			if path != "" {
+				childPath = path + "." + childPath
+			}
+			if err := w.replace(value, childPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// set sets a scalar node to a new random credential of the field, the bcrypt hash for hashed fields
+func (w *credentialWriter) set(node *yaml.Node, owner string, field credentialField) error {
+	secret, err := randomAPIKey()
+	if field.Kind == "password" {
+		secret, err = rand.Text(), nil
+	}
+	if err != nil {
+		return err
+	}
+
+	value := secret
+	if field.Hashed {
+		hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash the password of %s: %w", owner, err)
+		}
+		value = string(hash)
+	}
+
+	start, end, found := scalarSpan(w.lines, node)
+	if found {
+		w.edits = append(w.edits, lineEdit{Line: node.Line, Start: start, End: end, Text: scalarText("!!str", value)})
+	} else {
+		w.reencode = true
+	}
+	setScalar(node, value)
+	w.credentials = append(w.credentials, credential{Kind: field.Kind, Name: owner, Secret: secret, Hashed: field.Hashed})
+	return nil
+}
+
+// randomAPIKey returns 32 random bytes from crypto/rand as hex
+func randomAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate an API key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// setScalar sets the value of a scalar node as plain string
+func setScalar(node *yaml.Node, value string) {
+	node.Value = value
+	node.Tag = "!!str"
+	node.Style = 0
+}
+
+// isScalarList checks if every item of a sequence node is a scalar
+func isScalarList(node *yaml.Node) bool {
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+// contains checks if values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// showGeneratedCredentials prints the plaintext of the generated credentials once, only in an interactive terminal.
+// Otherwise the output may end up in CI logs, so it only points to access.yaml and, for the hashed passwords,
+// to the 0600 credentials file.
+func showGeneratedCredentials(projectDir string) {
+	if len(generatedCredentials) == 0 {
+		return
+	}
+	defer func() { generatedCredentials = nil }()
+
+	if outputMode != outputPretty || nonInteractive {
+		hashed := make([]string, 0)
+		for _, c := range generatedCredentials {
+			if c.Hashed {
+				hashed = append(hashed, fmt.Sprintf("%s %s: %s", c.Kind, noneIfEmpty(c.Name), c.Secret))
+			}
+		}
+		message := fmt.Sprintf("🔑 %d credential(s) generated in %s (mode 0600)", len(generatedCredentials), filepath.Join(projectDir, "access.yaml"))
+		if len(hashed) > 0 {
+			credentialsPath := filepath.Join(projectDir, credentialsFileName)
+			if err := os.WriteFile(credentialsPath, []byte(strings.Join(hashed, "\n")+"\n"), 0600); err != nil {
+				warn("", fmt.Sprintf("Failed to write the plaintext of the hashed passwords to %s: %v", credentialsPath, err))
+			} else {
+				// Ignored right away, the .gitignore of git-init isn't written when git-init is skipped
+				if err := addGitignoreEntries(projectDir, []string{"# Plaintext of the generated passwords", credentialsFileName}); err != nil {
+					warn("", fmt.Sprintf("Failed to add %s to .gitignore, keep it out of the repository: %v", credentialsFileName, err))
+				}
+				message += fmt.Sprintf(", the plaintext of the hashed passwords is in %s (mode 0600), delete it once they are stored", credentialsPath)
+			}
+		}
+		showMessage(message)
+		return
+	}
+
+	rows := make([][]string, 0, len(generatedCredentials))
+	for _, c := range generatedCredentials {
+		stored := "plaintext"
+		if c.Hashed {
+			stored = "bcrypt hash"
+		}
+		rows = append(rows, []string{c.Kind, noneIfEmpty(c.Name), c.Secret, stored})
+	}
+	showMessage("🔑 Generated credentials of access.yaml, store them now, they are not shown again:")
+	showTable([]string{"Type", "Name", "Secret", "Stored as"}, rows, tap.TableOptions{ShowBorders: true, IncludePrefix: true})
+}
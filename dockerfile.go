@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yarlson/tap"
+	"golang.org/x/mod/modfile"
+)
+
+// dockerignoreEntries keep the real secrets, the git history and local files out of the build context
+var dockerignoreEntries = []string{
+	".git",
+	".gitignore",
+	".env",
+	credentialsFileName,
+	"config.yaml",
+	"access.yaml",
+	"**/config.yaml",
+	"**/access.yaml",
+	"docker-compose.yaml",
+	"Dockerfile",
+	".dockerignore",
+	checkpointFileName,
+	"bin/",
+	"dist/",
+	"*.md",
+}
+
+// askDockerfile asks if a Dockerfile should be generated
+func askDockerfile(ctx context.Context, current bool) bool {
+	dockerfile := promptConfirm(ctx, tap.ConfirmOptions{
+		Message:      withSource("Generate a Dockerfile?", "dockerfile"),
+		InitialValue: current,
+	})
+
+	if dockerfile {
+		showMessage("✅ Dockerfile generation enabled")
+	} else {
+		showMessage("⏭️ Dockerfile generation disabled")
+	}
+	return dockerfile
+}
+
+// generateDockerfile writes a multi-stage Dockerfile and a .dockerignore for the project mode.
+// The image runs the static binary of webcore/main.go as non-root user, the real config files are mounted.
+func generateDockerfile(ctx context.Context, config *Config) error {
+	dockerfilePath := filepath.Join(config.ProjectDir, "Dockerfile")
+	dockerignorePath := filepath.Join(config.ProjectDir, ".dockerignore")
+	writeDockerfile := confirmOverwrite(ctx, dockerfilePath)
+	writeDockerignore := confirmOverwrite(ctx, dockerignorePath)
+
+	sp := newSpinner()
+	sp.Start("Generating Dockerfile...")
+
+	if writeDockerfile {
+		content, err := dockerfileContent(config)
+		if err != nil {
+			sp.Stop("❌ Failed to generate Dockerfile", 1)
+			return err
+		}
+		if err := os.WriteFile(dockerfilePath, content, 0644); err != nil {
+			sp.Stop("❌ Failed to write Dockerfile", 1)
+			return fmt.Errorf("failed to write Dockerfile: %w", err)
+		}
+	}
+
+	if writeDockerignore {
+		content := "# Generated by webcore-go-install, the real config.yaml and access.yaml hold secrets\n" + strings.Join(dockerignoreEntries, "\n") + "\n"
+		if err := os.WriteFile(dockerignorePath, []byte(content), 0644); err != nil {
+			sp.Stop("❌ Failed to write .dockerignore", 1)
+			return fmt.Errorf("failed to write .dockerignore: %w", err)
+		}
+	}
+
+	sp.Stop("✅ Dockerfile generated", 0)
+	return nil
+}
+
+// dockerfileContent builds the Dockerfile. Mono-repo mode copies go.work and every module of modules/,
+// simple mode only webcore/ and builds without the workspace.
+func dockerfileContent(config *Config) ([]byte, error) {
+	goVersion, err := projectGoVersion(config.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# syntax=docker/dockerfile:1\n")
+	buf.WriteString("# Generated by webcore-go-install\n\n")
+	buf.WriteString(fmt.Sprintf("FROM golang:%s-alpine AS build\n", goVersion))
+	buf.WriteString("WORKDIR /src\n\n")
+
+	buildEnv, buildPackage := "CGO_ENABLED=0", "./webcore"
+	if config.ProjectMode == "mono-repo" {
+		modules, err := workspaceModules(config.ProjectDir)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("COPY go.work go.work.sum* ./\n")
+		buf.WriteString("COPY webcore/ webcore/\n")
+		for _, module := range modules {
+			buf.WriteString(fmt.Sprintf("COPY %s/ %s/\n", module, module))
+		}
+	} else {
+		buf.WriteString("COPY webcore/ webcore/\n")
+		buf.WriteString("WORKDIR /src/webcore\n")
+		buildEnv, buildPackage = "CGO_ENABLED=0 GOWORK=off", "."
+	}
+
+	buf.WriteString("\n")
+	buf.WriteString("RUN --mount=type=cache,target=/go/pkg/mod \\\n")
+	buf.WriteString("    --mount=type=cache,target=/root/.cache/go-build \\\n")
+	buf.WriteString(fmt.Sprintf("    %s go build -trimpath -ldflags=\"-s -w\" -o /out/webcore %s\n\n", buildEnv, buildPackage))
+
+	buf.WriteString("FROM gcr.io/distroless/static-debian12:nonroot\n")
+	buf.WriteString("WORKDIR /app\n")
+	buf.WriteString("COPY --from=build /out/webcore /app/webcore\n")
+	buf.WriteString("# The image has no settings, mount the real config.yaml and access.yaml at /app/config.yaml and /app/access.yaml.\n")
+	buf.WriteString("# The examples are only included for reference, their API keys must never be used.\n")
+	buf.WriteString("COPY config.yaml.example access.yaml.example /app/\n")
+	buf.WriteString("USER nonroot:nonroot\n")
+	buf.WriteString("ENTRYPOINT [\"/app/webcore\"]\n")
+
+	return buf.Bytes(), nil
+}
+
+// projectGoVersion returns the Go version of webcore/go.mod as major.minor
+func projectGoVersion(projectDir string) (string, error) {
+	goModPath := filepath.Join(projectDir, "webcore", "go.mod")
+	goMod, err := readGoMod(goModPath)
+	if err != nil {
+		return "", err
+	}
+	if goMod.Go == nil {
+		return "", fmt.Errorf("%s has no go directive", goModPath)
+	}
+
+	parts := strings.SplitN(goMod.Go.Version, ".", 3)
+	if len(parts) < 2 {
+		return goMod.Go.Version, nil
+	}
+	return parts[0] + "." + parts[1], nil
+}
+
+// workspaceModules returns the existing module directories of modules/ listed in go.work, e.g. modules/orders
+func workspaceModules(projectDir string) ([]string, error) {
+	goWorkPath := filepath.Join(projectDir, "go.work")
+	content, err := os.ReadFile(goWorkPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.work: %w", err)
+	}
+	work, err := modfile.ParseWork(goWorkPath, content, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.work: %w", err)
+	}
+
+	modules := make([]string, 0, len(work.Use))
+	for _, use := range work.Use {
+		dir := filepath.ToSlash(filepath.Clean(use.Path))
+		if !strings.HasPrefix(dir, "modules/") || !fileExists(filepath.Join(projectDir, dir)) {
+			continue
+		}
+		modules = append(modules, dir)
+	}
+	return modules, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+)
+
+// Exit codes, documented in the README
+const (
+	exitCodeFailure       = 1   // unclassified failure
+	exitCodeWarnings      = 2   // completed with warnings in --strict mode
+	exitCodeInvalidInput  = 3   // invalid flag, answer or project directory
+	exitCodeGitMissing    = 4   // git not found in PATH
+	exitCodeGoMissing     = 5   // go not found in PATH
+	exitCodeTemplateFetch = 6   // the template couldn't be downloaded
+	exitCodeRewrite       = 7   // a step failed to rewrite the project files
+	exitCodeDependency    = 8   // go get failed to resolve a library
+	exitCodeVerification  = 9   // the project doesn't build after rename-module or convert
+	exitCodeInterrupted   = 130 // cancelled by the user, the shell convention for SIGINT
+)
+
+// installError is a failure with its own exit code and a hint how to fix it
+type installError struct {
+	Code int
+	Hint string
+	Err  error
+}
+
+func (e *installError) Error() string { return e.Err.Error() }
+
+func (e *installError) Unwrap() error { return e.Err }
+
+// newInstallError classifies err with an exit code and a remediation hint
+func newInstallError(code int, hint string, err error) error {
+	return &installError{Code: code, Hint: hint, Err: err}
+}
+
+// invalidInputError reports an invalid flag or answer
+func invalidInputError(hint string, err error) error {
+	return newInstallError(exitCodeInvalidInput, hint, err)
+}
+
+// exitCodeOf returns the exit code of err, exitCodeFailure when it isn't classified
+func exitCodeOf(err error) int {
+	var ie *installError
+	if errors.As(err, &ie) {
+		return ie.Code
+	}
+	return exitCodeFailure
+}
+
+// hintOf returns the remediation hint of err, if any
+func hintOf(err error) string {
+	var ie *installError
+	if errors.As(err, &ie) {
+		return ie.Hint
+	}
+	return ""
+}
+
+// errorMessage formats err with its hint for the outro
+func errorMessage(message string, err error) string {
+	text := fmt.Sprintf("❌ %v\n", err)
+	if message != "" {
+		text = fmt.Sprintf("❌ %s: %v\n", message, err)
+	}
+	if hint := hintOf(err); hint != "" {
+		text += "Hint: " + hint + "\n"
+	}
+	return text
+}
+
+// exitWithError prints the error with its hint and exits with its exit code
+func exitWithError(message string, err error) {
+	showOutro(errorMessage(message, err))
+	exit(exitCodeOf(err))
+}
+
+// lookTool checks that git or go is installed
+func lookTool(name string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		if name == "git" {
+			return newInstallError(exitCodeGitMissing, "git not found in PATH, install it from https://git-scm.com/downloads", err)
+		}
+		return newInstallError(exitCodeGoMissing, "go not found in PATH, install it from https://go.dev/dl/", err)
+	}
+	return nil
+}
+
+// checkTools fails early when a tool needed by the selected steps isn't installed
+func checkTools(config *Config, steps []installStep) error {
+	for _, step := range steps {
+		var err error
+		switch {
+		case step.Name == "go-get", step.Name == "go-work" && config.ProjectMode == "mono-repo":
+			err = lookTool("go")
+		case step.Name == "git-init" && config.GitInit:
+			err = lookTool("git")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commandError is the failure of an external command together with what it wrote to stderr
+type commandError struct {
+	Err    error
+	Stderr string
+}
+
+func (e *commandError) Error() string { return e.Err.Error() }
+
+func (e *commandError) Unwrap() error { return e.Err }
+
+// commandStderr returns the stderr of a failed external command
+func commandStderr(err error) string {
+	var ce *commandError
+	if errors.As(err, &ce) {
+		return ce.Stderr
+	}
+	return ""
+}
+
+// dependencyError classifies a failed go get of packagePath with a hint derived from its stderr
+func dependencyError(packagePath string, err error) error {
+	stderr := commandStderr(err)
+	hint := fmt.Sprintf("run go get %s in webcore to see the full error", packagePath)
+
+	switch {
+	case containsAny(stderr, "403", "404", "410", "terminal prompts disabled", "could not read Username", "Repository not found"):
+		hint = fmt.Sprintf("if %s is private, set GOPRIVATE=%s and configure git credentials for it", packagePath, privateModulePattern(packagePath))
+	case containsAny(stderr, "dial tcp", "i/o timeout", "no such host", "connection refused"):
+		hint = "check the network connection and the GOPROXY setting"
+	}
+	return newInstallError(exitCodeDependency, hint, fmt.Errorf("go get %s failed: %w", packagePath, err))
+}
+
+// privateModulePattern returns the GOPRIVATE pattern of the organization hosting the module, e.g. github.com/yourorg
+func privateModulePattern(path string) string {
+	elems := strings.Split(path, "/")
+	if len(elems) > 2 {
+		elems = elems[:2]
+	}
+	return strings.Join(elems, "/")
+}
+
+// containsAny checks if s contains any of the substrings
+func containsAny(s string, substrs ...string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// stepError classifies the failure of an install step that isn't classified yet
+func stepError(step string, err error) error {
+	var ie *installError
+	if errors.As(err, &ie) {
+		return &installError{Code: ie.Code, Hint: ie.Hint, Err: fmt.Errorf("step %s: %w", step, err)}
+	}
+
+	err = fmt.Errorf("step %s: %w", step, err)
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) && errors.Is(err, fs.ErrNotExist) {
+		return newInstallError(exitCodeRewrite, fmt.Sprintf("%s missing from template%s", pathErr.Path, templateCommitSuffix()), err)
+	}
+	return newInstallError(exitCodeRewrite, "", err)
+}
+
+// templateCommitSuffix names the downloaded template commit, if known
+func templateCommitSuffix() string {
+	if templateCommit == "" {
+		return ""
+	}
+	return " at commit " + templateCommit
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/yarlson/tap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultGitBranch        = "main"
+	defaultGitCommitMessage = "Initial commit from webcore-go-install"
+)
+
+// gitignoreEntries are the entries of the generated .gitignore, config.yaml and access.yaml hold secrets. They are
+// anchored to the project root, so a config.yaml elsewhere in the project (e.g. test data) stays tracked.
+var gitignoreEntries = []string{
+	"# Secrets, created from the example files",
+	"/config.yaml",
+	"/access.yaml",
+	".env",
+	credentialsFileName,
+	"",
+	"# Build output",
+	"/bin/",
+	"/dist/",
+	"*.exe",
+	"*.test",
+	"*.out",
+	"",
+	"# Installer checkpoint",
+	checkpointFileName,
+	"",
+	"# Editors and OS files",
+	".idea/",
+	".vscode/",
+	".DS_Store",
+}
+
+// gitAuthorPattern matches a git author in the form "Name <email>"
+var gitAuthorPattern = regexp.MustCompile(`^([^<>]+?)\s*<([^<>\s]+)>$`)
+
+// askGitBranch asks for the name of the initial branch, prefilled with current if set
+func askGitBranch(ctx context.Context, current string) (string, error) {
+	initialValue := defaultGitBranch
+	if current != "" {
+		initialValue = current
+	}
+	for {
+		branch := promptText(ctx, tap.TextOptions{
+			Message:      withSource("Enter the initial branch name", "git-branch"),
+			Placeholder:  defaultGitBranch,
+			InitialValue: initialValue,
+		})
+
+		if err := validateBranchName(branch); err != nil {
+			if nonInteractive {
+				return "", invalidInputError("use a branch name like main", fmt.Errorf("invalid branch name %q: %w", branch, err))
+			}
+			showMessage(fmt.Sprintf("❌ Invalid branch name: %v", err))
+			continue
+		}
+
+		showMessage(fmt.Sprintf("✅ Initial branch: %s\n", branch))
+		return branch, nil
+	}
+}
+
+// validateBranchName checks the most common rules of git check-ref-format for a branch name
+func validateBranchName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("branch name is empty")
+	case strings.ContainsAny(name, " ~^:?*[\\"), strings.Contains(name, ".."), strings.Contains(name, "@{"):
+		return errors.New("branch name contains a space, '..', '@{' or one of ~^:?*[\\")
+	case strings.HasPrefix(name, "-"), strings.HasPrefix(name, "/"), strings.HasSuffix(name, "/"),
+		strings.HasSuffix(name, "."), strings.HasSuffix(name, ".lock"):
+		return errors.New("branch name can't start with '-' or '/', or end with '/', '.' or '.lock'")
+	}
+	return nil
+}
+
+// askGitRemote asks for the URL of the origin remote, an empty result adds no remote
+func askGitRemote(ctx context.Context, current string) (string, error) {
+	addRemote := promptConfirm(ctx, tap.ConfirmOptions{
+		Message:      withSource("Add a remote origin?", "git-remote"),
+		InitialValue: current != "",
+	})
+	if !addRemote {
+		showMessage("⏭️ No remote origin")
+		return "", nil
+	}
+
+	for {
+		remote := promptText(ctx, tap.TextOptions{
+			Message:      withSource("Enter the remote origin URL", "git-remote"),
+			Placeholder:  "git@github.com:yourorg/project.git",
+			InitialValue: current,
+		})
+
+		if strings.ContainsAny(remote, " \t") {
+			if nonInteractive {
+				return "", invalidInputError("use a git URL like git@github.com:yourorg/project.git", fmt.Errorf("invalid remote URL %q", remote))
+			}
+			showMessage("❌ Invalid remote URL: it contains whitespace")
+			continue
+		}
+		if remote == "" {
+			showMessage("⏭️ No remote origin")
+			return "", nil
+		}
+
+		showMessage(fmt.Sprintf("✅ Remote origin: %s\n", remote))
+		return remote, nil
+	}
+}
+
+// askGitCommit asks if the installed project should be committed, which it is by default
+func askGitCommit(ctx context.Context, current bool) bool {
+	if answerSource("git-commit") == sourceBuiltin {
+		current = true
+	}
+	commit := promptConfirm(ctx, tap.ConfirmOptions{
+		Message:      withSource("Create an initial commit?", "git-commit"),
+		InitialValue: current,
+	})
+
+	if commit {
+		showMessage("✅ Initial commit enabled")
+	} else {
+		showMessage("⏭️ Initial commit disabled")
+	}
+	return commit
+}
+
+// parseGitAuthor splits a git author in the form "Name <email>"
+func parseGitAuthor(author string) (string, string, error) {
+	match := gitAuthorPattern.FindStringSubmatch(strings.TrimSpace(author))
+	if match == nil {
+		return "", "", fmt.Errorf("expected \"Name <email>\", got %q", author)
+	}
+	return match[1], match[2], nil
+}
+
+// initGit initializes the git repository with its initial branch, .gitignore, remote and initial commit.
+// When the project directory is inside an existing work tree, only the .gitignore is written.
+func initGit(ctx context.Context, config *Config) error {
+	sp := newSpinner()
+	sp.Start("Initializing git repository...")
+
+	topLevel := gitTopLevel(config.ProjectDir)
+	ownRepo := fileExists(filepath.Join(config.ProjectDir, ".git"))
+
+	if err := writeGitignore(config.ProjectDir); err != nil {
+		sp.Stop("❌ Failed to write .gitignore", 1)
+		return err
+	}
+
+	if topLevel != "" && !ownRepo {
+		sp.Stop(fmt.Sprintf("⏭️ %s is inside the git work tree %s, skipped git init", config.ProjectDir, topLevel), 0)
+		return nil
+	}
+
+	if !ownRepo {
+		branch := config.GitBranch
+		if branch == "" {
+			branch = defaultGitBranch
+		}
+		if err := runGit(ctx, config.ProjectDir, "init", "--initial-branch="+branch); err != nil {
+			sp.Stop("❌ Failed to initialize git", 1)
+			return fmt.Errorf("git init failed: %w", err)
+		}
+	}
+
+	if config.GitRemote != "" && !hasGitRemote(config.ProjectDir, "origin") {
+		if err := runGit(ctx, config.ProjectDir, "remote", "add", "origin", config.GitRemote); err != nil {
+			sp.Stop("❌ Failed to add the remote origin", 1)
+			return fmt.Errorf("git remote add failed: %w", err)
+		}
+	}
+
+	var secretFiles []string
+	if config.GitCommit && !hasGitCommit(config.ProjectDir) {
+		var err error
+		if secretFiles, err = commitProject(ctx, config); err != nil {
+			sp.Stop("❌ Failed to create the initial commit", 1)
+			return err
+		}
+	}
+
+	sp.Stop("✅ Git repository initialized", 0)
+	for _, file := range secretFiles {
+		warn("git-init", fmt.Sprintf("The initial commit includes %s, which contains a secret, remove it from the history before pushing", file))
+	}
+	return nil
+}
+
+// commitProject commits every file of the project that isn't ignored.
+// It returns the committed files that contain a secret.
+func commitProject(ctx context.Context, config *Config) ([]string, error) {
+	if err := runGit(ctx, config.ProjectDir, "add", "--all"); err != nil {
+		return nil, fmt.Errorf("git add failed: %w", err)
+	}
+	secretFiles, err := stagedSecretFiles(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	message := config.GitCommitMessage
+	if message == "" {
+		message = defaultGitCommitMessage
+	}
+	cmd := exec.CommandContext(ctx, "git", "commit", "--quiet", "--message", message)
+	cmd.Dir = config.ProjectDir
+
+	// The configured author is also the committer, so the commit works without a git identity
+	if config.GitAuthor != "" {
+		name, email, err := parseGitAuthor(config.GitAuthor)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+name, "GIT_AUTHOR_EMAIL="+email,
+			"GIT_COMMITTER_NAME="+name, "GIT_COMMITTER_EMAIL="+email)
+	}
+
+	if err := runExternal(ctx, cmd); err != nil {
+		hint := ""
+		if strings.Contains(commandStderr(err), "Please tell me who you are") {
+			hint = "set the commit author with WEBCORE_GIT_AUTHOR=\"Name <email>\", or configure git user.name and user.email"
+		}
+		return nil, newInstallError(exitCodeFailure, hint, fmt.Errorf("git commit failed: %w", err))
+	}
+	return secretFiles, nil
+}
+
+// stagedSecretFiles returns the staged files that contain a secret of this run or a Kubernetes Secret with values
+func stagedSecretFiles(ctx context.Context, config *Config) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--cached", "--name-only", "-z")
+	cmd.Dir = config.ProjectDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+
+	// Values of the example files are public, e.g. a password kept from config.yaml.example
+	var examples []byte
+	for _, name := range []string{"config.yaml.example", "access.yaml.example"} {
+		content, _ := os.ReadFile(filepath.Join(config.ProjectDir, name))
+		examples = append(examples, content...)
+	}
+	secrets := make([]string, 0)
+	for _, secret := range config.ServiceSecrets {
+		if !bytes.Contains(examples, []byte(secret)) {
+			secrets = append(secrets, secret)
+		}
+	}
+	for _, c := range generatedCredentials {
+		secrets = append(secrets, c.Secret)
+	}
+
+	files := make([]string, 0)
+	for _, name := range strings.Split(strings.TrimRight(string(out), "\x00"), "\x00") {
+		if name == "" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(config.ProjectDir, name))
+		if err != nil {
+			continue
+		}
+		isYAML := strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+		if containsSecret(content, secrets) || (isYAML && hasSecretValues(content)) {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// containsSecret checks if content contains one of the secrets, short values are ignored as they match by chance
+func containsSecret(content []byte, secrets []string) bool {
+	for _, secret := range secrets {
+		if len(secret) >= 6 && bytes.Contains(content, []byte(secret)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSecretValues checks if a YAML file holds a Kubernetes Secret with a value that isn't empty
+func hasSecretValues(content []byte) bool {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc struct {
+			Kind       string            `yaml:"kind"`
+			Data       map[string]string `yaml:"data"`
+			StringData map[string]string `yaml:"stringData"`
+		}
+		if err := decoder.Decode(&doc); err != nil {
+			return false
+		}
+		if doc.Kind != "Secret" {
+			continue
+		}
+		for _, values := range []map[string]string{doc.Data, doc.StringData} {
+			for _, value := range values {
+				if value != "" {
+					return true
+				}
+			}
+		}
+	}
+}
+
+// runGit runs a git command in dir
+func runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	return runExternal(ctx, cmd)
+}
+
+// gitTopLevel returns the top level directory of the git work tree dir is in, empty when it isn't in one
+func gitTopLevel(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// hasGitRemote checks if the repository in dir has the named remote
+func hasGitRemote(dir, name string) bool {
+	return exec.Command("git", "-C", dir, "remote", "get-url", name).Run() == nil
+}
+
+// hasGitCommit checks if the repository in dir has a commit
+func hasGitCommit(dir string) bool {
+	return exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", "HEAD").Run() == nil
+}
+
+// isGitDone detects that the git step has already been applied
+func isGitDone(config *Config) bool {
+	if !config.GitInit {
+		return true
+	}
+	if !fileExists(filepath.Join(config.ProjectDir, ".git")) {
+		return false
+	}
+	return (config.GitRemote == "" || hasGitRemote(config.ProjectDir, "origin")) &&
+		(!config.GitCommit || hasGitCommit(config.ProjectDir))
+}
+
+// writeGitignore adds the entries of gitignoreEntries that are missing in the .gitignore of the project
+func writeGitignore(projectDir string) error {
+	return addGitignoreEntries(projectDir, gitignoreEntries)
+}
+
+// addGitignoreEntries adds the entries that are missing in the .gitignore of the project, creating it if needed.
+// The comments of entries are written along with the missing entries.
+func addGitignoreEntries(projectDir string, entries []string) error {
+	path := filepath.Join(projectDir, ".gitignore")
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .gitignore: %w", err)
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(string(content), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	missing := false
+	for _, entry := range entries {
+		if entry != "" && !strings.HasPrefix(entry, "#") && !existing[entry] {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return nil
+	}
+
+	var b strings.Builder
+	b.Write(content)
+	if len(content) > 0 {
+		if !strings.HasSuffix(string(content), "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	for _, entry := range entries {
+		if entry != "" && !strings.HasPrefix(entry, "#") && existing[entry] {
+			continue
+		}
+		b.WriteString(entry + "\n")
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write .gitignore: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddGitignoreEntries(t *testing.T) {
+	entries := []string{"# Plaintext of the generated passwords", credentialsFileName}
+	tests := []struct {
+		name     string
+		existing string // content of .gitignore, none if empty
+		want     string
+	}{
+		{name: "no .gitignore", want: "# Plaintext of the generated passwords\n.credentials\n"},
+		{name: "appended", existing: "/bin/", want: "/bin/\n\n# Plaintext of the generated passwords\n.credentials\n"},
+		{name: "already ignored", existing: "/bin/\n.credentials\n", want: "/bin/\n.credentials\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, ".gitignore")
+			if tt.existing != "" {
+				writeTestFile(t, dir, ".gitignore", tt.existing)
+			}
+
+			if err := addGitignoreEntries(dir, entries); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.want {
+				t.Errorf(".gitignore = %q, want %q", content, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,22 @@ module github.com/semanggilab/webcore-go-install
 
 go 1.25.0
 
-require github.com/yarlson/tap v0.11.0
+require (
+	github.com/dlclark/regexp2 v1.11.5
+	github.com/mattn/go-tty v0.0.7
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
+	github.com/yarlson/tap v0.11.0
+	golang.org/x/crypto v0.46.0
+	golang.org/x/mod v0.30.0
+	golang.org/x/sys v0.39.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/clipperhouse/stringish v0.1.1 // indirect
 	github.com/clipperhouse/uax29/v2 v2.3.0 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mattn/go-runewidth v0.0.19 // indirect
-	github.com/mattn/go-tty v0.0.7 // indirect
-	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/term v0.38.0 // indirect
+	golang.org/x/text v0.32.0 // indirect
 )
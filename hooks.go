@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yarlson/tap"
+	"gopkg.in/yaml.v3"
+)
+
+const templateManifestFile = "webcore-template.yaml"
+
+// Hook represents a command that runs before or after a named install step
+type Hook struct {
+	Name   string   `yaml:"name"`
+	Step   string   `yaml:"step"`
+	When   string   `yaml:"when"` // "before" or "after"
+	Run    string   `yaml:"run"`  // shell command, run with sh -c
+	Exec   []string `yaml:"exec"` // external executable followed by its arguments
+	Source string   `yaml:"-"`    // file the hook was declared in
+	Remote bool     `yaml:"-"`    // whether the hook comes from the manifest of a template downloaded from a remote source
+}
+
+// templateManifest holds the installer settings shipped with the template
+type templateManifest struct {
+	Hooks []Hook `yaml:"hooks"`
+}
+
+// loadHooks loads hooks from the template manifest and the user config file
+func loadHooks(projectDir string) ([]Hook, error) {
+	hooks := make([]Hook, 0)
+
+	manifestPath := filepath.Join(projectDir, templateManifestFile)
+	content, err := os.ReadFile(manifestPath)
+	if err == nil {
+		var manifest templateManifest
+		if err := yaml.Unmarshal(content, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", manifestPath, err)
+		}
+		for _, hook := range manifest.Hooks {
+			hook.Source = manifestPath
+			hook.Remote = !isLocalTemplate(templateSource)
+			hooks = append(hooks, hook)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", manifestPath, err)
+	}
+
+	userConfig, err := loadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range userConfig.Hooks {
+		hook.Source = userConfig.path
+		hooks = append(hooks, hook)
+	}
+
+	for _, hook := range hooks {
+		if err := validateHook(hook); err != nil {
+			return nil, fmt.Errorf("invalid hook %s in %s: %w", hook.displayName(), hook.Source, err)
+		}
+	}
+
+	return hooks, nil
+}
+
+// validateHook checks that the hook targets a known step and has a command to run
+func validateHook(hook Hook) error {
+	if !isStepName(hook.Step) {
+		return fmt.Errorf("unknown step %q (available: %s)", hook.Step, strings.Join(stepNames(), ", "))
+	}
+	if hook.When != "before" && hook.When != "after" {
+		return fmt.Errorf("when must be \"before\" or \"after\", got %q", hook.When)
+	}
+	if (hook.Run == "") == (len(hook.Exec) == 0) {
+		return fmt.Errorf("exactly one of run or exec must be set")
+	}
+	return nil
+}
+
+// displayName returns the hook name, or its command if no name is set
+func (h Hook) displayName() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	if h.Run != "" {
+		return h.Run
+	}
+	return strings.Join(h.Exec, " ")
+}
+
+// isLocalTemplate checks if the template source is a directory on this machine instead of a remote repository
+func isLocalTemplate(source string) bool {
+	if strings.HasPrefix(source, "file://") {
+		return true
+	}
+	info, err := os.Stat(source)
+	return err == nil && info.IsDir()
+}
+
+// hooksSummary returns one line per hook with its step, command and origin, for the review
+func hooksSummary(hooks []Hook) string {
+	lines := make([]string, 0, len(hooks))
+	for _, hook := range hooks {
+		origin := "user config"
+		if filepath.Base(hook.Source) == templateManifestFile {
+			origin = "template"
+			if hook.Remote {
+				origin = "remote template"
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s-%s: %s (%s)", hook.When, hook.Step, hook.displayName(), origin))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// confirmHooks asks before the hooks of a remote template run, as they run arbitrary commands.
+// With yes they run without asking, declined hooks are skipped and the other hooks are kept.
+func confirmHooks(ctx context.Context, hooks []Hook, yes bool) ([]Hook, error) {
+	local := make([]Hook, 0, len(hooks))
+	for _, hook := range hooks {
+		if !hook.Remote {
+			local = append(local, hook)
+		}
+	}
+	remote := len(hooks) - len(local)
+	if remote == 0 || yes {
+		return hooks, nil
+	}
+
+	if nonInteractive {
+		err := fmt.Errorf("%s of the template %s declares %d hook(s), which run commands on this machine", templateManifestFile, templateSource, remote)
+		return nil, invalidInputError("review the hooks and run them with --yes, or skip all hooks with --no-hooks", err)
+	}
+
+	run := promptConfirm(ctx, tap.ConfirmOptions{
+		Message:      fmt.Sprintf("Run the %d hook(s) of the template %s? They run commands on this machine", remote, templateSource),
+		InitialValue: false,
+	})
+	if run {
+		showMessage("✅ Template hooks confirmed")
+		return hooks, nil
+	}
+	showMessage("⏭️ Skipping the hooks of the template")
+	return local, nil
+}
+
+// runHooks runs all hooks declared for the given step and moment
+func runHooks(ctx context.Context, config *Config, hooks []Hook, step, when string) error {
+	for _, hook := range hooks {
+		if hook.Step != step || hook.When != when {
+			continue
+		}
+
+		showMessage(fmt.Sprintf("🪝 Running %s-%s hook: %s", when, step, hook.displayName()))
+		if err := runHook(ctx, config, hook); err != nil {
+			err = fmt.Errorf("%s-%s hook %q from %s failed: %w", when, step, hook.displayName(), hook.Source, err)
+			return newInstallError(exitCodeFailure, fmt.Sprintf("fix or remove the hook in %s", hook.Source), err)
+		}
+	}
+	return nil
+}
+
+// runHook executes a single hook in the project directory.
+// The resolved Config is passed as WEBCORE_* environment variables and as JSON on stdin.
+func runHook(ctx context.Context, config *Config, hook Hook) error {
+	var cmd *exec.Cmd
+	if hook.Run != "" {
+		cmd = exec.CommandContext(ctx, "sh", "-c", hook.Run)
+	} else {
+		cmd = exec.CommandContext(ctx, hook.Exec[0], hook.Exec[1:]...)
+	}
+
+	payload, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	cmd.Dir = config.ProjectDir
+	cmd.Env = append(os.Environ(), configEnv(config)...)
+	cmd.Env = append(cmd.Env, "WEBCORE_HOOK_STEP="+hook.Step, "WEBCORE_HOOK_WHEN="+hook.When)
+	cmd.Stdin = bytes.NewReader(payload)
+
+	return runExternal(ctx, cmd)
+}
+
+// configEnv returns the Config as a list of WEBCORE_* environment variables
+func configEnv(config *Config) []string {
+	libraries := make([]string, 0, len(config.SelectedLibraries))
+	for _, lib := range config.SelectedLibraries {
+		libraries = append(libraries, lib.Name)
+	}
+
+	features := make([]string, 0, len(config.SelectedFeatures))
+	for _, feature := range config.SelectedFeatures {
+		features = append(features, feature.Name)
+	}
+
+	projectDir, err := filepath.Abs(config.ProjectDir)
+	if err != nil {
+		projectDir = config.ProjectDir
+	}
+
+	return []string{
+		"WEBCORE_PROJECT_DIR=" + projectDir,
+		"WEBCORE_MODULE_NAME=" + config.ModuleName,
+		"WEBCORE_LIBRARIES=" + strings.Join(libraries, ","),
+		"WEBCORE_MODE=" + config.ProjectMode,
+		"WEBCORE_FOLDER=" + config.FolderName,
+		"WEBCORE_PACKAGE=" + config.PackageName,
+		"WEBCORE_MODULE_MOD_NAME=" + config.ModuleModName,
+		"WEBCORE_FEATURES=" + strings.Join(features, ","),
+		"WEBCORE_GIT_INIT=" + strconv.FormatBool(config.GitInit),
+		"WEBCORE_GIT_BRANCH=" + config.GitBranch,
+		"WEBCORE_GIT_REMOTE=" + config.GitRemote,
+	}
+}
@@ -3,14 +3,21 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"go/token"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/yarlson/tap"
+	"golang.org/x/mod/module"
 )
 
 const (
@@ -19,27 +26,36 @@ const (
 	defaultProjectDir = "./webcore"
 )
 
+// templateSource is the git URL or path of the template, templateRepoURL unless a config file sets another one
+var templateSource = templateRepoURL
+
 // LibraryOption represents a library option for selection
 type LibraryOption struct {
-	Name        string
-	Description string
-	PackagePath string
-	LoaderName  string
-	Enabled     bool
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	PackagePath string          `json:"package_path"`
+	LoaderName  string          `json:"loader_name,omitempty"`
+	Service     *backingService `json:"-"` // container that runs the service locally, nil when none is needed
+	Enabled     bool            `json:"-"`
 }
 
+// kafkaService is the broker shared by the Kafka producer and consumer
+var kafkaService = &backingService{Name: "kafka", Image: "apache/kafka", Tag: "3.8.0", Port: 9092, Section: "kafka"}
+
 // Available libraries based on webcore/deps/libraries.go
 var availableLibraries = []LibraryOption{
 	{
 		Name:        "database:postgres",
 		Description: "PostgreSQL",
 		PackagePath: "github.com/webcore-go/lib-postgres",
+		Service:     &backingService{Name: "postgres", Image: "postgres", Tag: "16.4-alpine", Port: 5432, Section: "database"},
 		Enabled:     true,
 	},
 	{
 		Name:        "database:mysql",
 		Description: "MySQL",
 		PackagePath: "github.com/webcore-go/lib-mysql",
+		Service:     &backingService{Name: "mysql", Image: "mysql", Tag: "8.4.2", Port: 3306, Section: "database"},
 		Enabled:     false,
 	},
 	{
@@ -52,12 +68,14 @@ var availableLibraries = []LibraryOption{
 		Name:        "database:mongodb",
 		Description: "MongoDB",
 		PackagePath: "github.com/webcore-go/lib-mongo",
+		Service:     &backingService{Name: "mongo", Image: "mongo", Tag: "7.0.14", Port: 27017, Section: "database"},
 		Enabled:     false,
 	},
 	{
 		Name:        "redis",
 		Description: "Redis",
 		PackagePath: "github.com/webcore-go/lib-redis",
+		Service:     &backingService{Name: "redis", Image: "redis", Tag: "7.4.0-alpine", Port: 6379, Section: "redis"},
 		Enabled:     false,
 	},
 	{
@@ -65,6 +83,7 @@ var availableLibraries = []LibraryOption{
 		Description: "Kafka Producer",
 		PackagePath: "github.com/webcore-go/lib-kafka",
 		LoaderName:  "KafkaProducerLoader",
+		Service:     kafkaService,
 		Enabled:     false,
 	},
 	{
@@ -72,6 +91,7 @@ var availableLibraries = []LibraryOption{
 		Description: "Kafka Consumer",
 		PackagePath: "github.com/webcore-go/lib-kafka",
 		LoaderName:  "KafkaConsumerLoader",
+		Service:     kafkaService,
 		Enabled:     false,
 	},
 	{
@@ -79,6 +99,7 @@ var availableLibraries = []LibraryOption{
 		Description: "Google Pub/Sub",
 		PackagePath: "github.com/webcore-go/lib-pubsub",
 		LoaderName:  "PubSubLoader",
+		Service:     &backingService{Name: "pubsub", Image: "gcr.io/google.com/cloudsdktool/google-cloud-cli", Tag: "494.0.0-emulators", Port: 8085, Section: "pubsub"},
 		Enabled:     false,
 	},
 	{
@@ -105,9 +126,9 @@ var availableLibraries = []LibraryOption{
 
 // Feature represents a feature option
 type Feature struct {
-	Name        string
-	Description string
-	Enabled     bool
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"-"`
 }
 
 var availableFeatures = []Feature{
@@ -130,81 +151,463 @@ var availableFeatures = []Feature{
 
 // Config holds the installation configuration
 type Config struct {
-	ProjectDir        string
-	ModuleName        string
-	SelectedLibraries []LibraryOption
-	ProjectMode       string // "simple" or "mono-repo"
-	FolderName        string // for mono-repo mode
-	ModuleModName     string // for mono-repo mode
-	SelectedFeatures  []Feature
-	GitInit           bool // whether to initialize git
+	ProjectDir        string            `json:"project_dir"`
+	ModuleName        string            `json:"module_name"`
+	SelectedLibraries []LibraryOption   `json:"libraries"`
+	ProjectMode       string            `json:"mode"`                      // "simple" or "mono-repo"
+	FolderName        string            `json:"folder,omitempty"`          // for mono-repo mode
+	PackageName       string            `json:"package,omitempty"`         // for mono-repo mode, also used as import alias
+	ModuleModName     string            `json:"module_mod_name,omitempty"` // for mono-repo mode
+	SelectedFeatures  []Feature         `json:"features"`
+	GitInit           bool              `json:"git_init"` // whether to initialize git
+	GitBranch         string            `json:"git_branch,omitempty"`
+	GitRemote         string            `json:"git_remote,omitempty"`
+	GitCommit         bool              `json:"git_commit,omitempty"` // whether to create an initial commit
+	GitCommitMessage  string            `json:"git_commit_message,omitempty"`
+	GitAuthor         string            `json:"git_author,omitempty"`       // "Name <email>", the git identity by default
+	Dockerfile        bool              `json:"dockerfile,omitempty"`       // whether to generate a Dockerfile
+	CI                string            `json:"ci,omitempty"`               // CI provider: github, gitlab, makefile or empty for none
+	Kubernetes        string            `json:"kubernetes,omitempty"`       // Kubernetes files: manifests, helm or empty for none
+	ServiceConfig     bool              `json:"service_config,omitempty"`   // whether to write the service settings into config.yaml
+	ServiceSettings   map[string]string `json:"service_settings,omitempty"` // config.yaml settings by section.key, without secrets
+	ServiceSecrets    map[string]string `json:"-"`                          // secret settings, never written to the checkpoint or lock file
+	SecretsEnvFile    bool              `json:"secrets_env_file,omitempty"` // whether the secrets go to .env instead of config.yaml
 }
 
 func main() {
-	ctx := context.Background()
+	outputMode = detectOutputMode()
+
+	// Subcommands and plugins: webcore-go-install <command> [args...]
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	flags := flag.NewFlagSet("webcore-go-install", flag.ExitOnError)
+	skipFlag := flags.String("skip", "", "comma separated list of install steps to skip ("+strings.Join(stepNames(), ", ")+")")
+	onlyFlag := flags.String("only", "", "comma separated list of install steps to run, all other steps are skipped")
+	flags.BoolVar(&nonInteractive, "non-interactive", false, "don't ask questions, use the default answers")
+	dirFlag := flags.String("dir", "", "project directory, skips the question")
+	resumeFlag := flags.Bool("resume", false, "resume a failed installation from its checkpoint with the same answers")
+	outputFlag := flags.String("output", "", "output mode: pretty, plain or json (default: plain when stdout isn't a terminal or NO_COLOR is set)")
+	reportFlag := flags.String("report", "", "write a JSON installation report to this file")
+	reportMarkdownFlag := flags.String("report-markdown", "", "write a Markdown installation report to this file")
+	ciFlag := flags.String("ci", "", "generate a CI pipeline: github, gitlab, makefile or none, skips the question")
+	presetFlag := flags.String("preset", "", "preselect the libraries, mode and features of a preset, skips the preset question")
+	strictFlag := flags.Bool("strict", false, "exit with a non-zero code when the installation completed with warnings")
+	noHooksFlag := flags.Bool("no-hooks", false, "don't run any hooks of the template manifest or the user config file")
+	yesFlag := flags.Bool("yes", false, "run the hooks of a remote template without asking")
+	answersFlag := flags.String("answers", "", "YAML file with answers, used for the answers no WEBCORE_* variable sets")
+	flags.Parse(os.Args[1:])
+
+	var err error
+	outputMode, err = parseOutputMode(*outputFlag)
+	if err != nil {
+		exitWithError("Invalid --output value", invalidInputError("", err))
+	}
+
+	skipSteps, err := parseStepList(*skipFlag)
+	if err != nil {
+		exitWithError("Invalid --skip value", invalidInputError("", err))
+	}
+	onlySteps, err := parseStepList(*onlyFlag)
+	if err != nil {
+		exitWithError("Invalid --only value", invalidInputError("", err))
+	}
+	steps := selectSteps(onlySteps, skipSteps)
+
+	ciProvider, err := parseCIProvider(*ciFlag)
+	if err != nil {
+		exitWithError("Invalid --ci value", invalidInputError("", err))
+	}
+
+	presets, err := loadPresets()
+	if err != nil {
+		exitWithError("Failed to load presets", err)
+	}
+	var preset *Preset
+	if *presetFlag != "" {
+		if preset, err = findPreset(presets, *presetFlag); err != nil {
+			exitWithError("Invalid --preset value", err)
+		}
+	}
 
-	tap.Intro("WebCore Go Template Installer")
-	tap.Message("This installer will help you set up a new WebCore Go project")
+	ctx, stop := newInterruptContext()
+	defer stop()
 
+	showIntro("WebCore Go Template Installer")
+	showMessage("This installer will help you set up a new WebCore Go project")
+
+	// Answers are prefilled from the WEBCORE_* environment variables, then from the answers file, flags take precedence
 	config := &Config{}
+	if err := applyEnvAnswers(config); err != nil {
+		exitWithError("", err)
+	}
+	if *answersFlag != "" {
+		if err := applyAnswersFile(config, *answersFlag); err != nil {
+			exitWithError("", err)
+		}
+	}
 
 	// Step 1: Ask for project directory
-	config.ProjectDir = askProjectDir(ctx)
+	if *dirFlag != "" {
+		config.ProjectDir = cleanProjectDir(*dirFlag)
+		answerSources["dir"] = "--dir"
+	} else {
+		config.ProjectDir = askProjectDir(ctx, config.ProjectDir)
+	}
+	if *ciFlag != "" {
+		config.CI = ciProvider
+		answerSources["ci"] = "--ci"
+	}
 
-	// Step 2: Download template
-	if err := downloadTemplate(config.ProjectDir); err != nil {
-		tap.Outro(fmt.Sprintf("❌ Failed to download template: %v\n", err))
-		os.Exit(1)
+	// Answers that are still unset are prefilled from the user and organization config files,
+	// a module name that is still unset is derived from the surrounding repository
+	if err := applyDefaultAnswers(config); err != nil {
+		exitWithError("", err)
 	}
+	applyInferredModuleName(config)
 
-	// Step 2: Ask for module name
-	config.ModuleName = askModuleName(ctx)
+	// Step 2: Check the project directory and download template
+	dirAction := dirActionResume
+	if !*resumeFlag {
+		dirAction, err = prepareProjectDir(ctx, config)
+		if err != nil {
+			exitWithError("", err)
+		}
+	}
+	var cloned *clonedTemplate
+	switch dirAction {
+	case dirActionClone:
+		if cloned, err = downloadTemplate(ctx, config.ProjectDir); err != nil {
+			if ctx.Err() != nil {
+				exitCancelled()
+			}
+			exitWithError("Failed to download template", err)
+		}
+	case dirActionReconfigure:
+		steps = selectSteps(onlySteps, append(skipSteps, excludedSteps(reconfigureSteps)...))
+	}
 
-	// Step 3: Select libraries
-	config.SelectedLibraries = selectLibraries(ctx)
+	// Step 3: Ask the configuration questions, or take the answers from the checkpoint when resuming
+	var cp *checkpoint
+	if dirAction == dirActionResume {
+		cp, err = readCheckpoint(config.ProjectDir)
+		if err != nil && (*resumeFlag || !os.IsNotExist(err)) {
+			exitWithError("Cannot resume installation", invalidInputError("start a new installation without --resume", err))
+		}
+		if cp != nil {
+			templateCommit = cp.TemplateCommit
+		}
+	}
 
-	// Step 4: Select project mode
-	config.ProjectMode = selectProjectMode(ctx)
+	switch {
+	case cp != nil:
+		config = cp.Config
+		showMessage(fmt.Sprintf("♻️ Resuming installation, completed steps: %s", strings.Join(cp.CompletedSteps, ", ")))
+		if config.ServiceConfig && !cp.isCompleted("service-settings") {
+			err = askServiceSecrets(ctx, config)
+		}
+	case dirAction == dirActionReconfigure:
+		err = askReconfigureQuestions(ctx, config)
+	default:
+		if preset == nil && !nonInteractive {
+			preset = selectPreset(ctx, presets)
+		}
+		if preset != nil {
+			err = applyPreset(config, preset)
+		}
+		if err == nil {
+			err = askQuestions(ctx, config)
+		}
+	}
+	if err != nil {
+		exitWithError("", err)
+	}
 
-	// Step 5: Handle project mode specific configuration
-	if config.ProjectMode == "mono-repo" {
-		config.FolderName = askFolderName(ctx)
-		config.ModuleModName = askModuleModName(ctx, config.ModuleName, config.FolderName)
+	// Step 4: Load pre- and post-step hooks from the template manifest and user config
+	var hooks []Hook
+	if *noHooksFlag {
+		showMessage("⏭️ Hooks disabled with --no-hooks")
+	} else if hooks, err = loadHooks(config.ProjectDir); err != nil {
+		exitWithError("Failed to load hooks", invalidInputError("fix the hook definitions", err))
 	}
 
-	// Step 6: Select features
-	config.SelectedFeatures = selectFeatures(ctx)
+	// Step 5: Review the answers and hooks before anything is changed, hooks of a remote template need a confirmation
+	if cp == nil {
+		var editable []string
+		if dirAction == dirActionReconfigure {
+			editable = []string{"libraries"}
+		}
+		if err := reviewConfig(ctx, config, hooks, editable, cloned); err != nil {
+			exitWithError("", err)
+		}
+		cp = newCheckpoint(config)
+	} else if len(hooks) > 0 {
+		showMessage(fmt.Sprintf("🪝 Hooks:\n%s", hooksSummary(hooks)))
+	}
+	if hooks, err = confirmHooks(ctx, hooks, *yesFlag); err != nil {
+		exitWithError("Hooks not confirmed", err)
+	}
+	cp.resume = dirAction == dirActionResume
+	if err := cp.save(); err != nil {
+		exitWithError(fmt.Sprintf("Failed to write %s", checkpointFileName), err)
+	}
+	resumeHint = fmt.Sprintf("Continue with: webcore-go-install --resume --dir %s", config.ProjectDir)
+
+	if err := checkTools(config, steps); err != nil {
+		exitWithError("Missing tool", err)
+	}
+
+	currentReport, err = startReport(config, *reportFlag, *reportMarkdownFlag)
+	if err != nil {
+		exitWithError("Failed to start the installation report", err)
+	}
+
+	// Step 6: Apply configuration
+	results, err := applyConfiguration(ctx, config, steps, hooks, cp)
+	if err != nil {
+		finishReport(results, err, ctx.Err() != nil)
+		showGeneratedCredentials(config.ProjectDir)
+		if ctx.Err() != nil {
+			exitCancelled()
+		}
+		showOutro(errorMessage("Failed to apply configuration", err) + "Fix the problem and " + strings.ToLower(resumeHint[:1]) + resumeHint[1:] + "\n")
+		exit(exitCodeOf(err))
+	}
 
-	// Step 7: Ask about git initialization
-	config.GitInit = askGitInit(ctx)
+	if err := writeLockFile(config); err != nil {
+		exitWithError(fmt.Sprintf("Failed to write %s", lockFileName), err)
+	}
+	if err := cp.remove(); err != nil {
+		warn("", fmt.Sprintf("Failed to remove %s: %v", checkpointFileName, err))
+	}
 
-	// Step 8: Apply configuration
-	if err := applyConfiguration(ctx, config); err != nil {
-		tap.Outro(fmt.Sprintf("❌ Failed to apply configuration: %v\n", err))
-		os.Exit(1)
+	// In strict mode warnings fail the installation, a failed go get with its own exit code
+	var strictErr error
+	if *strictFlag && len(warnings) > 0 {
+		strictErr = dependencyErr
+		if strictErr == nil {
+			strictErr = newInstallError(exitCodeWarnings, "fix the warnings above, or run without --strict", fmt.Errorf("%d warning(s)", len(warnings)))
+		}
+	}
+	finishReport(results, strictErr, false)
+	showGeneratedCredentials(config.ProjectDir)
+	if strictErr != nil {
+		exitWithError("Installation completed with warnings in strict mode", strictErr)
 	}
 
-	tap.Outro(fmt.Sprintf("✅ Installation completed successfully!\nYou can now run your project with: cd %s && make run", config.ProjectDir))
+	showOutro(fmt.Sprintf("✅ Installation completed successfully!\nYou can now run your project with: cd %s && make run", config.ProjectDir))
 }
 
-// downloadTemplate downloads the template from GitHub
-func downloadTemplate(projectDir string) error {
-	sp := tap.NewSpinner(tap.SpinnerOptions{Indicator: "dots"})
-	sp.Start("Downloading template from GitHub...")
+// askQuestions asks all configuration questions of a new installation, except those answered by a flag
+func askQuestions(ctx context.Context, config *Config) error {
+	for _, field := range []string{"module", "libraries", "service-config", "mode", "features", "git", "dockerfile", "ci", "kubernetes"} {
+		if isFlagSource(answerSources[field]) {
+			continue
+		}
+		if err := askField(ctx, config, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// Check if project directory already exists
-	if _, err := os.Stat(fmt.Sprintf("%s/webcore/go.mod", projectDir)); err == nil {
-		sp.Stop(fmt.Sprintf("⚠️ Project already initialized in %s directory, skipping download", projectDir), 0)
-		return nil
+// askField asks the question(s) for a single configuration field, prefilled with the current value
+func askField(ctx context.Context, config *Config, field string) error {
+	var err error
+	defer func() {
+		if err == nil && !nonInteractive {
+			answerSources[field] = sourceAnswer
+		}
+	}()
+
+	switch field {
+	case "module":
+		config.ModuleName, err = askModuleName(ctx, config.ModuleName)
+	case "libraries":
+		config.SelectedLibraries = selectLibraries(ctx, config.SelectedLibraries)
+	case "service-config":
+		err = askServiceSettings(ctx, config)
+	case "mode":
+		config.ProjectMode = selectProjectMode(ctx, config.ProjectMode)
+
+		// Handle project mode specific configuration
+		if config.ProjectMode != "mono-repo" {
+			config.FolderName, config.PackageName, config.ModuleModName = "", "", ""
+			return nil
+		}
+		for _, modeField := range []string{"folder", "package", "module-mod"} {
+			if err := askField(ctx, config, modeField); err != nil {
+				return err
+			}
+		}
+	case "folder":
+		config.FolderName, err = askFolderName(ctx, config.FolderName)
+	case "package":
+		config.PackageName, err = askPackageName(ctx, config.FolderName, config.PackageName)
+	case "module-mod":
+		config.ModuleModName, err = askModuleModName(ctx, config.ModuleName, config.FolderName, config.ModuleModName)
+	case "features":
+		config.SelectedFeatures = selectFeatures(ctx, config.SelectedFeatures)
+	case "git":
+		config.GitInit = askGitInit(ctx, config.GitInit)
+
+		if !config.GitInit {
+			config.GitBranch, config.GitRemote, config.GitCommit = "", "", false
+			return nil
+		}
+		for _, gitField := range []string{"git-branch", "git-remote", "git-commit"} {
+			if err := askField(ctx, config, gitField); err != nil {
+				return err
+			}
+		}
+	case "git-branch":
+		config.GitBranch, err = askGitBranch(ctx, config.GitBranch)
+	case "git-remote":
+		config.GitRemote, err = askGitRemote(ctx, config.GitRemote)
+	case "git-commit":
+		config.GitCommit = askGitCommit(ctx, config.GitCommit)
+	case "dockerfile":
+		config.Dockerfile = askDockerfile(ctx, config.Dockerfile)
+	case "ci":
+		config.CI = selectCIProvider(ctx, config.CI)
+	case "kubernetes":
+		config.Kubernetes = selectKubernetesFormat(ctx, config.Kubernetes)
+	}
+	return err
+}
+
+// askReconfigureQuestions starts from the configuration in the lock file of an installed project
+// and only asks the questions of the steps that can be applied again
+func askReconfigureQuestions(ctx context.Context, config *Config) error {
+	lock, err := readLockFile(filepath.Join(config.ProjectDir, lockFileName))
+	if err != nil {
+		return err
+	}
+	projectDir := config.ProjectDir
+	*config = *lock.Config
+	config.ProjectDir = projectDir
+	for _, f := range reviewFields {
+		answerSources[f.Field] = lockFileName
+	}
+
+	return askField(ctx, config, "libraries")
+}
+
+// runCommand runs a built-in subcommand or a webcore-go-install-<name> plugin and returns the exit code
+func runCommand(name string, args []string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), interruptSignals...)
+	defer stop()
+
+	var err error
+	switch name {
+	case "plugins":
+		err = runPluginsCommand(args)
+	case "rename-module":
+		err = runRenameModuleCommand(ctx, args)
+	case "convert":
+		err = runConvertCommand(ctx, args)
+	default:
+		code, err := runPlugin(ctx, name, args)
+		if err != nil {
+			showOutro(errorMessage("", err))
+		}
+		return code
+	}
+
+	if err != nil {
+		showOutro(errorMessage("", err))
+		if ctx.Err() != nil {
+			return exitCodeInterrupted
+		}
+		return exitCodeOf(err)
 	}
+	return 0
+}
 
-	cmd := exec.Command("git", "clone", "--depth", "1", templateRepoURL, projectDir)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// clonedTemplate is what downloadTemplate put on disk, so a cancelled installation removes only that
+type clonedTemplate struct {
+	Dir     string   // project directory
+	Created bool     // whether the clone created the project directory
+	Entries []string // entries the clone added to the project directory
+}
 
-	if err := cmd.Run(); err != nil {
+// remove removes the downloaded template: the project directory if the clone created it, otherwise only
+// the entries the clone added. The current working directory itself is never removed.
+func (t *clonedTemplate) remove() error {
+	if t.Created && !isWorkingDir(t.Dir) {
+		return os.RemoveAll(t.Dir)
+	}
+	for _, entry := range t.Entries {
+		if err := os.RemoveAll(filepath.Join(t.Dir, entry)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isWorkingDir checks if dir is the current working directory
+func isWorkingDir(dir string) bool {
+	wd, err := os.Getwd()
+	if err != nil {
+		return true
+	}
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	wdInfo, err := os.Stat(wd)
+	return err == nil && os.SameFile(dirInfo, wdInfo)
+}
+
+// newEntries returns the entries of dir that aren't in before
+func newEntries(dir string, before map[string]bool) []string {
+	entries, _ := os.ReadDir(dir)
+	added := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !before[entry.Name()] {
+			added = append(added, entry.Name())
+		}
+	}
+	return added
+}
+
+// downloadTemplate downloads the template from GitHub and returns what it put on disk.
+// An interrupted download is removed again.
+func downloadTemplate(ctx context.Context, projectDir string) (*clonedTemplate, error) {
+	sp := newSpinner()
+	sp.Start(fmt.Sprintf("Downloading template from %s...", templateSource))
+
+	if err := lookTool("git"); err != nil {
 		sp.Stop("❌ Failed to download template", 1)
-		return fmt.Errorf("git clone failed: %w", err)
+		return nil, err
+	}
+
+	cloned := &clonedTemplate{Dir: projectDir, Created: !fileExists(projectDir)}
+	before := make(map[string]bool)
+	if entries, err := os.ReadDir(projectDir); err == nil {
+		for _, entry := range entries {
+			before[entry.Name()] = true
+		}
+	}
+	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", templateSource, projectDir)
+
+	err := runExternal(ctx, cmd)
+	cloned.Entries = newEntries(projectDir, before)
+	if err != nil {
+		if ctx.Err() != nil {
+			sp.Stop("❌ Download interrupted", 1)
+			_ = cloned.remove()
+			return nil, ctx.Err()
+		}
+		sp.Stop("❌ Failed to download template", 1)
+		hint := fmt.Sprintf("check network access to %s, or clone it into the project directory yourself and run the installer again", templateSource)
+		return nil, newInstallError(exitCodeTemplateFetch, hint, fmt.Errorf("git clone failed: %w", err))
+	}
+
+	// Remember the template commit before the history is removed
+	if out, err := exec.CommandContext(ctx, "git", "-C", projectDir, "rev-parse", "--short", "HEAD").Output(); err == nil {
+		templateCommit = strings.TrimSpace(string(out))
 	}
 
 	// Remove .git directory from cloned repo
@@ -214,50 +617,89 @@ func downloadTemplate(projectDir string) error {
 	}
 
 	sp.Stop("✅ Template downloaded successfully", 0)
-	return nil
+	return cloned, nil
 }
 
-// askProjectDir asks for the project directory
-func askProjectDir(ctx context.Context) string {
-	projectDir := tap.Text(ctx, tap.TextOptions{
-		Message:      "Enter project directory",
+// askProjectDir asks for the project directory, prefilled with current if set
+func askProjectDir(ctx context.Context, current string) string {
+	initialValue := defaultProjectDir
+	if current != "" {
+		initialValue = current
+	}
+	projectDir := promptText(ctx, tap.TextOptions{
+		Message:      withSource("Enter project directory", "dir"),
 		Placeholder:  defaultProjectDir,
-		InitialValue: defaultProjectDir,
+		InitialValue: initialValue,
+		Validate: func(value string) error {
+			if strings.TrimSpace(value) == "" {
+				return errors.New("a project directory is required")
+			}
+			return nil
+		},
 	})
 
-	// Clean up the directory path (remove trailing slashes)
-	projectDir = strings.TrimSuffix(projectDir, "/")
-	projectDir = strings.TrimSuffix(projectDir, "\\")
+	projectDir = cleanProjectDir(projectDir)
 
-	tap.Message(fmt.Sprintf("✅ Project directory: %s\n", projectDir))
+	showMessage(fmt.Sprintf("✅ Project directory: %s\n", projectDir))
 	return projectDir
 }
 
-// askModuleName asks for the Go module name
-func askModuleName(ctx context.Context) string {
-	moduleName := tap.Text(ctx, tap.TextOptions{
-		Message:      "Enter Go module name",
-		Placeholder:  defaultModuleName,
-		InitialValue: defaultModuleName,
-	})
+// cleanProjectDir cleans up the directory path (remove trailing slashes)
+func cleanProjectDir(projectDir string) string {
+	projectDir = strings.TrimSuffix(projectDir, "/")
+	projectDir = strings.TrimSuffix(projectDir, "\\")
+	return projectDir
+}
 
-	// Validate module name format
-	if !isValidModuleName(moduleName) {
-		tap.Message("⚠️ Module name format is not standard, but continuing anyway")
+// askModuleName asks for the Go module name, prefilled with current if set
+func askModuleName(ctx context.Context, current string) (string, error) {
+	initialValue := defaultModuleName
+	if current != "" {
+		initialValue = current
 	}
+	for {
+		moduleName := promptText(ctx, tap.TextOptions{
+			Message:      withSource("Enter Go module name", "module"),
+			Placeholder:  defaultModuleName,
+			InitialValue: initialValue,
+		})
+
+		// Validate module name format
+		if err := validateModuleName(moduleName); err != nil {
+			if nonInteractive {
+				return "", invalidInputError("use a module path like github.com/yourorg/project", fmt.Errorf("invalid module name %q: %w", moduleName, err))
+			}
+			showMessage(fmt.Sprintf("❌ Invalid module name: %v", err))
+			continue
+		}
 
-	tap.Message(fmt.Sprintf("✅ Module name set to: %s\n", moduleName))
-	return moduleName
+		showMessage(fmt.Sprintf("✅ Module name set to: %s\n", moduleName))
+		return moduleName, nil
+	}
 }
 
-// isValidModuleName checks if the module name follows Go module naming conventions
-func isValidModuleName(name string) bool {
-	// Basic validation: should contain at least one slash and no spaces
-	return strings.Contains(name, "/") && !strings.Contains(name, " ")
+// validateModuleName checks if the module name is a valid Go module path.
+// It follows module.CheckPath and additionally rejects uppercase letters, the returned error holds the reason.
+func validateModuleName(name string) error {
+	if err := module.CheckPath(name); err != nil {
+		var pathErr *module.InvalidPathError
+		if errors.As(err, &pathErr) {
+			return pathErr.Err
+		}
+		return err
+	}
+
+	for _, elem := range strings.Split(name, "/") {
+		if strings.ToLower(elem) != elem {
+			return fmt.Errorf("path element %q contains uppercase", elem)
+		}
+	}
+
+	return nil
 }
 
-// selectLibraries displays library selection options
-func selectLibraries(ctx context.Context) []LibraryOption {
+// selectLibraries displays library selection options, preselecting current if it isn't nil
+func selectLibraries(ctx context.Context, current []LibraryOption) []LibraryOption {
 	// Create options for MultiSelect
 	options := make([]tap.SelectOption[string], len(availableLibraries))
 	defaultValues := make([]string, 0)
@@ -267,14 +709,17 @@ func selectLibraries(ctx context.Context) []LibraryOption {
 			Value: lib.Name,
 			Label: fmt.Sprintf("%s", lib.Description),
 		}
-		if lib.Enabled {
+		if current == nil && lib.Enabled {
 			defaultValues = append(defaultValues, lib.Name)
 		}
 	}
+	for _, lib := range current {
+		defaultValues = append(defaultValues, lib.Name)
+	}
 
 	// Use MultiSelect for multiple choices
-	selectedNames := tap.MultiSelect(ctx, tap.MultiSelectOptions[string]{
-		Message:       "Select libraries to include in your project",
+	selectedNames := promptMultiSelect(ctx, tap.MultiSelectOptions[string]{
+		Message:       withSource("Select libraries to include in your project", "libraries"),
 		Options:       options,
 		InitialValues: defaultValues,
 	})
@@ -294,42 +739,53 @@ func selectLibraries(ctx context.Context) []LibraryOption {
 		}
 	}
 
-	tap.Message(fmt.Sprintf("✅ Selected: %v\n", selectedStrings))
+	showMessage(fmt.Sprintf("✅ Selected: %v\n", selectedStrings))
 	return selected
 }
 
-// selectProjectMode asks for the project mode
-func selectProjectMode(ctx context.Context) string {
-	mode := tap.Select(ctx, tap.SelectOptions[string]{
+// selectProjectMode asks for the project mode, preselecting current if set
+func selectProjectMode(ctx context.Context, current string) string {
+	initialValue := "mono-repo"
+	if current != "" {
+		initialValue = current
+	}
+	mode := promptSelect(ctx, tap.SelectOptions[string]{
 		Message: "Choose project type",
 		Options: []tap.SelectOption[string]{
 			{Label: "Mono-repo (multiple modules)", Value: "mono-repo"},
 			{Label: "Simple (single module)", Value: "simple"},
 		},
-		InitialValue: &[]string{"mono-repo"}[0],
+		InitialValue: &initialValue,
 	})
 
-	tap.Message(fmt.Sprintf("✅ Project type: %s\n", mode))
+	showMessage(fmt.Sprintf("✅ Project type: %s\n", mode))
 	return mode
 }
 
-// askFolderName asks for the folder name in mono-repo mode
-func askFolderName(ctx context.Context) string {
+// askFolderName asks for the folder name in mono-repo mode, prefilled with current if set
+func askFolderName(ctx context.Context, current string) (string, error) {
+	initialValue := "mymodule"
+	if current != "" {
+		initialValue = current
+	}
 	for {
-		folderName := tap.Text(ctx, tap.TextOptions{
-			Message:      "Enter module folder name",
+		folderName := promptText(ctx, tap.TextOptions{
+			Message:      withSource("Enter module folder name", "folder"),
 			Placeholder:  "mymodule",
-			InitialValue: "mymodule",
+			InitialValue: initialValue,
 		})
 
 		// Validate folder name
 		if !isValidFolderName(folderName) {
-			tap.Message("❌ Invalid folder name. Use only lowercase letters, numbers, and hyphens")
+			if nonInteractive {
+				return "", invalidInputError("use only lowercase letters, numbers, and hyphens", fmt.Errorf("invalid folder name %q", folderName))
+			}
+			showMessage("❌ Invalid folder name. Use only lowercase letters, numbers, and hyphens")
 			continue
 		}
 
-		tap.Message(fmt.Sprintf("✅ Folder name: %s\n", folderName))
-		return folderName
+		showMessage(fmt.Sprintf("✅ Folder name: %s\n", folderName))
+		return folderName, nil
 	}
 }
 
@@ -340,21 +796,104 @@ func isValidFolderName(name string) bool {
 	return matched && len(name) > 0
 }
 
+// askPackageName asks for the Go package name of the module, derived from the folder name by default
+func askPackageName(ctx context.Context, folderName string, current string) (string, error) {
+	defaultPkgName := derivePackageName(folderName)
+	initialValue := defaultPkgName
+	if current != "" {
+		initialValue = current
+	}
+	for {
+		pkgName := promptText(ctx, tap.TextOptions{
+			Message:      withSource("Enter Go package name for this module", "package"),
+			Placeholder:  defaultPkgName,
+			InitialValue: initialValue,
+		})
+
+		if err := validatePackageName(pkgName); err != nil {
+			if nonInteractive {
+				return "", invalidInputError("use a lowercase Go identifier that isn't a keyword", fmt.Errorf("invalid package name %q: %w", pkgName, err))
+			}
+			showMessage(fmt.Sprintf("❌ Invalid package name: %v", err))
+			continue
+		}
+
+		showMessage(fmt.Sprintf("✅ Package name: %s\n", pkgName))
+		return pkgName, nil
+	}
+}
+
+// derivePackageName derives a Go package identifier from a folder name (user-service becomes userservice)
+func derivePackageName(folderName string) string {
+	return strings.ReplaceAll(strings.ToLower(folderName), "-", "")
+}
+
+// predeclaredIdentifiers are the identifiers of the Go universe block, which can't be used as package names
+var predeclaredIdentifiers = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true, "int16": true, "int32": true,
+	"int64": true, "rune": true, "string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true,
+	"uint64": true, "uintptr": true, "true": true, "false": true, "iota": true, "nil": true, "append": true,
+	"cap": true, "clear": true, "close": true, "complex": true, "copy": true, "delete": true, "imag": true,
+	"len": true, "make": true, "max": true, "min": true, "new": true, "panic": true, "print": true,
+	"println": true, "real": true, "recover": true,
+}
+
+// validatePackageName checks if the name can be used as Go package name and import alias
+func validatePackageName(name string) error {
+	if name == "" {
+		return fmt.Errorf("package name is empty")
+	}
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("%q is not a valid Go identifier (use letters, digits and underscores, not starting with a digit)", name)
+	}
+	if token.IsKeyword(name) {
+		return fmt.Errorf("%q is a Go keyword", name)
+	}
+	if predeclaredIdentifiers[name] {
+		return fmt.Errorf("%q is a predeclared Go identifier", name)
+	}
+	if name == "main" || name == "_" {
+		return fmt.Errorf("%q can't be used as module package name", name)
+	}
+	return nil
+}
+
 // askModuleModName asks for the Go module name in mono-repo mode
-func askModuleModName(ctx context.Context, projectModuleName string, folderName string) string {
-	defaultModName := fmt.Sprintf("%s-mod-%s", projectModuleName, folderName)
-	moduleName := tap.Text(ctx, tap.TextOptions{
-		Message:      "Enter Go module name for this module",
-		Placeholder:  defaultModName,
-		InitialValue: defaultModName,
-	})
+func askModuleModName(ctx context.Context, projectModuleName string, folderName string, current string) (string, error) {
+	defaultModName := defaultModuleModName(projectModuleName, folderName)
+	initialValue := defaultModName
+	if current != "" {
+		initialValue = current
+	}
+	for {
+		moduleName := promptText(ctx, tap.TextOptions{
+			Message:      withSource("Enter Go module name for this module", "module-mod"),
+			Placeholder:  defaultModName,
+			InitialValue: initialValue,
+		})
 
-	tap.Message(fmt.Sprintf("✅ Module name: %s\n", moduleName))
-	return moduleName
+		if err := validateModuleName(moduleName); err != nil {
+			if nonInteractive {
+				return "", invalidInputError("use a module path like github.com/yourorg/project", fmt.Errorf("invalid module name %q: %w", moduleName, err))
+			}
+			showMessage(fmt.Sprintf("❌ Invalid module name: %v", err))
+			continue
+		}
+
+		showMessage(fmt.Sprintf("✅ Module name: %s\n", moduleName))
+		return moduleName, nil
+	}
+}
+
+// defaultModuleModName returns the default Go module name of a module in mono-repo mode,
+// the project module name followed by the module directory (github.com/yourorg/project/modules/orders)
+func defaultModuleModName(projectModuleName string, folderName string) string {
+	return path.Join(projectModuleName, "modules", folderName)
 }
 
-// selectFeatures displays feature selection options
-func selectFeatures(ctx context.Context) []Feature {
+// selectFeatures displays feature selection options, preselecting current if it isn't nil
+func selectFeatures(ctx context.Context, current []Feature) []Feature {
 	// Create options for MultiSelect
 	options := make([]tap.SelectOption[string], len(availableFeatures))
 	defaultValues := make([]string, 0)
@@ -364,14 +903,17 @@ func selectFeatures(ctx context.Context) []Feature {
 			Value: feature.Name,
 			Label: fmt.Sprintf("%s", feature.Description),
 		}
-		if feature.Enabled {
+		if current == nil && feature.Enabled {
 			defaultValues = append(defaultValues, feature.Name)
 		}
 	}
+	for _, feature := range current {
+		defaultValues = append(defaultValues, feature.Name)
+	}
 
 	// Use MultiSelect for multiple choices
-	selectedNames := tap.MultiSelect(ctx, tap.MultiSelectOptions[string]{
-		Message:       "Select features to include in your module",
+	selectedNames := promptMultiSelect(ctx, tap.MultiSelectOptions[string]{
+		Message:       withSource("Select features to include in your module", "features"),
 		Options:       options,
 		InitialValues: defaultValues,
 	})
@@ -391,93 +933,48 @@ func selectFeatures(ctx context.Context) []Feature {
 		}
 	}
 
-	tap.Message(fmt.Sprintf("✅ Selected: %v\n", selectedStrings))
+	showMessage(fmt.Sprintf("✅ Selected: %v\n", selectedStrings))
 	return selected
 }
 
 // askGitInit asks if user wants to initialize git
-func askGitInit(ctx context.Context) bool {
-	gitInit := tap.Confirm(ctx, tap.ConfirmOptions{
-		Message:      "Initialize git repository?",
-		InitialValue: false,
+func askGitInit(ctx context.Context, current bool) bool {
+	gitInit := promptConfirm(ctx, tap.ConfirmOptions{
+		Message:      withSource("Initialize git repository?", "git"),
+		InitialValue: current,
 	})
 
 	if gitInit {
-		fmt.Println("✅ Git initialization enabled")
+		showMessage("✅ Git initialization enabled")
 	} else {
-		fmt.Println("⏭️ Git initialization disabled")
+		showMessage("⏭️ Git initialization disabled")
 	}
 
 	return gitInit
 }
 
-// applyConfiguration applies all the configuration changes
-func applyConfiguration(ctx context.Context, config *Config) error {
-	// Step 0. Replace module name in webcore/main.go
-	mainGoPath := filepath.Join(config.ProjectDir, "webcore", "main.go")
-	if err := replaceInFile(mainGoPath, "github.com/semanggilab/webcorego-template-app", config.ModuleName); err != nil {
-		return fmt.Errorf("failed to update webcore/main.go: %w", err)
-	}
-
-	// Step 1: Update webcore/go.mod with main module name
-	if err := updateWebcoreGoMod(config.ProjectDir, config.ModuleName); err != nil {
-		return fmt.Errorf("failed to update webcore/go.mod: %w", err)
-	}
-
-	// Step 2: Update webcore/deps/libraries.go with selected libraries
-	if err := updateLibrariesGo(config.ProjectDir, config.SelectedLibraries); err != nil {
-		return fmt.Errorf("failed to update libraries.go: %w", err)
-	}
-
-	// Step 3: Install selected libraries
-	if err := installLibraries(config.ProjectDir, config.SelectedLibraries); err != nil {
-		return fmt.Errorf("failed to install libraries: %w", err)
-	}
-
-	// Step 4: Copy example config files
-	if err := copyConfigFiles(config); err != nil {
-		return fmt.Errorf("failed to copy config files: %w", err)
-	}
-
-	// Step 5: Handle project mode
-	if config.ProjectMode == "mono-repo" {
-		if err := applyMonoRepoMode(config); err != nil {
-			return fmt.Errorf("failed to apply mono-repo mode: %w", err)
-		}
-	} else {
-		if err := applySimpleMode(config); err != nil {
-			return fmt.Errorf("failed to apply simple mode: %w", err)
-		}
-	}
-
-	// Step 5: Update webcore/deps/packages.go
-	if err := updatePackagesGo(config); err != nil {
-		return fmt.Errorf("failed to update packages.go: %w", err)
-	}
-
-	// Step 6: Cleanup dummy folder if it still exists
-	if err := cleanupDummyFolder(config.ProjectDir); err != nil {
-		return fmt.Errorf("failed to cleanup dummy folder: %w", err)
-	}
-
-	// Step 7: Update go.work file
-	if err := updateGoWork(config); err != nil {
-		return fmt.Errorf("failed to update go.work: %w", err)
-	}
+// applyConfiguration applies all the configuration changes by running the install steps
+func applyConfiguration(ctx context.Context, config *Config, steps []installStep, hooks []Hook, cp *checkpoint) ([]stepResult, error) {
+	start := time.Now()
+	results, err := runSteps(ctx, config, steps, hooks, cp)
 
-	// Step 8: Initialize git if requested
-	if config.GitInit {
-		if err := initGit(ctx, config.ProjectDir); err != nil {
-			return fmt.Errorf("failed to initialize git: %w", err)
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		duration := "-"
+		if result.Status != "skipped" {
+			duration = result.Duration.Round(time.Millisecond).String()
 		}
+		rows = append(rows, []string{result.Name, result.Status, duration})
 	}
+	showTable([]string{"Step", "Status", "Duration"}, rows, tap.TableOptions{ShowBorders: true, IncludePrefix: true})
+	showMessage(fmt.Sprintf("⏱️ Total time: %s", time.Since(start).Round(time.Millisecond)))
 
-	return nil
+	return results, err
 }
 
 // updateWebcoreGoMod updates the module name in webcore/go.mod
 func updateWebcoreGoMod(projectDir, moduleName string) error {
-	tap.Message("📝 Updating webcore/go.mod...")
+	showMessage("📝 Updating webcore/go.mod...")
 
 	goModPath := filepath.Join(projectDir, "webcore/go.mod")
 	content, err := os.ReadFile(goModPath)
@@ -498,13 +995,13 @@ func updateWebcoreGoMod(projectDir, moduleName string) error {
 		return err
 	}
 
-	tap.Message(fmt.Sprintf("✅ Updated module name to: %s\n", moduleName))
+	showMessage(fmt.Sprintf("✅ Updated module name to: %s\n", moduleName))
 	return nil
 }
 
 // updateLibrariesGo creates a new webcore/deps/libraries.go file with selected libraries
 func updateLibrariesGo(projectDir string, libraries []LibraryOption) error {
-	tap.Message("📝 Updating webcore/deps/libraries.go...")
+	showMessage("📝 Updating webcore/deps/libraries.go...")
 
 	libPath := filepath.Join(projectDir, "webcore", "deps", "libraries.go")
 
@@ -540,7 +1037,7 @@ func updateLibrariesGo(projectDir string, libraries []LibraryOption) error {
 		return err
 	}
 
-	tap.Message("✅ Updated webcore/deps/libraries.go with selected libraries")
+	showMessage("✅ Updated webcore/deps/libraries.go with selected libraries")
 	return nil
 }
 
@@ -562,21 +1059,24 @@ func getLoaderName(lib LibraryOption) string {
 }
 
 // installLibraries runs go get for each selected library
-func installLibraries(projectDir string, libraries []LibraryOption) error {
-	sp := tap.NewSpinner(tap.SpinnerOptions{Indicator: "dots"})
+func installLibraries(ctx context.Context, projectDir string, libraries []LibraryOption) error {
+	sp := newSpinner()
 	sp.Start("Installing selected libraries...")
 
 	webcoreDir := filepath.Join(projectDir, "webcore")
 	for _, lib := range libraries {
 		sp.Start(fmt.Sprintf("Installing: %s", lib.PackagePath))
 
-		cmd := exec.Command("go", "get", lib.PackagePath)
+		cmd := exec.CommandContext(ctx, "go", "get", lib.PackagePath)
 		cmd.Dir = webcoreDir
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
 
-		if err := cmd.Run(); err != nil {
-			tap.Message(fmt.Sprintf("⚠️ Failed to install %s: %v\n", lib.PackagePath, err))
+		if err := runExternal(ctx, cmd); err != nil {
+			if ctx.Err() != nil {
+				sp.Stop("❌ Library installation interrupted", 1)
+				return ctx.Err()
+			}
+			dependencyErr = dependencyError(lib.PackagePath, err)
+			warn("go-get", fmt.Sprintf("Failed to install %s: %v\nHint: %s\n", lib.PackagePath, err, hintOf(dependencyErr)))
 			continue
 		}
 	}
@@ -585,31 +1085,40 @@ func installLibraries(projectDir string, libraries []LibraryOption) error {
 	return nil
 }
 
-// copyConfigFiles copies example config files to project directory
-func copyConfigFiles(config *Config) error {
-	sp := tap.NewSpinner(tap.SpinnerOptions{Indicator: "dots"})
-	sp.Start("Copying example config files...")
-
-	// Copy config.yaml.example to config.yaml
+// copyConfigFiles copies example config files to project directory.
+// Existing config files are only overwritten after confirmation.
+func copyConfigFiles(ctx context.Context, config *Config) error {
 	configSrc := filepath.Join(config.ProjectDir, "config.yaml.example")
 	configDst := filepath.Join(config.ProjectDir, "config.yaml")
-	if err := copyFile(configSrc, configDst); err != nil {
-		sp.Stop("❌ Failed to copy config.yaml", 1)
-		return fmt.Errorf("failed to copy config.yaml: %w", err)
-	}
+	accessSrc := filepath.Join(config.ProjectDir, "access.yaml.example")
+	accessDst := filepath.Join(config.ProjectDir, "access.yaml")
+
+	copyConfig := confirmOverwrite(ctx, configDst)
+	copyAccess := confirmOverwrite(ctx, accessDst)
+
+	sp := newSpinner()
+	sp.Start("Copying example config files...")
 
-	// Comment out sections based on selected libraries
-	if err := commentConfigSections(configDst, config.SelectedLibraries); err != nil {
-		sp.Stop("❌ Failed to update config.yaml", 1)
-		return fmt.Errorf("failed to update config.yaml: %w", err)
+	if copyConfig {
+		// Copy config.yaml.example to config.yaml
+		if err := copyFile(configSrc, configDst); err != nil {
+			sp.Stop("❌ Failed to copy config.yaml", 1)
+			return fmt.Errorf("failed to copy config.yaml: %w", err)
+		}
+
+		// Comment out sections based on selected libraries
+		if err := commentConfigSections(configDst, config.SelectedLibraries); err != nil {
+			sp.Stop("❌ Failed to update config.yaml", 1)
+			return fmt.Errorf("failed to update config.yaml: %w", err)
+		}
 	}
 
-	// Copy access.yaml.example to access.yaml
-	accessSrc := filepath.Join(config.ProjectDir, "access.yaml.example")
-	accessDst := filepath.Join(config.ProjectDir, "access.yaml")
-	if err := copyFile(accessSrc, accessDst); err != nil {
-		sp.Stop("❌ Failed to copy access.yaml", 1)
-		return fmt.Errorf("failed to copy access.yaml: %w", err)
+	if copyAccess {
+		// Create access.yaml from access.yaml.example with fresh credentials
+		if err := writeAccessFile(accessSrc, accessDst, config.SelectedLibraries); err != nil {
+			sp.Stop("❌ Failed to copy access.yaml", 1)
+			return fmt.Errorf("failed to copy access.yaml: %w", err)
+		}
 	}
 
 	sp.Stop("✅ Example config files copied", 0)
@@ -700,7 +1209,7 @@ func commentSection(lines []string, start, end int, shouldComment bool) []string
 
 // applyMonoRepoMode applies mono-repo mode configuration
 func applyMonoRepoMode(config *Config) error {
-	sp := tap.NewSpinner(tap.SpinnerOptions{Indicator: "dots"})
+	sp := newSpinner()
 	sp.Start("Applying mono-repo mode...")
 
 	dummyPath := filepath.Join(config.ProjectDir, "modules", "dummy")
@@ -711,8 +1220,9 @@ func applyMonoRepoMode(config *Config) error {
 		sp.Stop("❌ Failed to rename folder", 1)
 		return fmt.Errorf("failed to rename folder: %w", err)
 	}
+	recordRename(dummyPath, newPath)
 
-	tap.Message(fmt.Sprintf("✅ Renamed modules/dummy to modules/%s\n", config.FolderName))
+	showMessage(fmt.Sprintf("✅ Renamed modules/dummy to modules/%s\n", config.FolderName))
 
 	// Replace module name in go.mod
 	goModPath := filepath.Join(newPath, "go.mod")
@@ -722,7 +1232,7 @@ func applyMonoRepoMode(config *Config) error {
 	}
 
 	// Replace package name and import paths in all Go files
-	if err := replacePackageNames(newPath, "dummy", config.FolderName, "github.com/semanggilab/webcorego-template-mod", config.ModuleModName); err != nil {
+	if err := replacePackageNames(newPath, "dummy", config.PackageName, "github.com/semanggilab/webcorego-template-mod", config.ModuleModName); err != nil {
 		sp.Stop("❌ Failed to update package names", 1)
 		return fmt.Errorf("failed to update package names: %w", err)
 	}
@@ -739,7 +1249,7 @@ func applyMonoRepoMode(config *Config) error {
 
 // applySimpleMode applies simple mode configuration
 func applySimpleMode(config *Config) error {
-	sp := tap.NewSpinner(tap.SpinnerOptions{Indicator: "dots"})
+	sp := newSpinner()
 	sp.Start("Applying simple mode...")
 
 	dummyPath := filepath.Join(config.ProjectDir, "modules", "dummy")
@@ -770,6 +1280,7 @@ func applySimpleMode(config *Config) error {
 			sp.Stop("❌ Failed to move files", 1)
 			return fmt.Errorf("failed to move %s: %w", entry.Name(), err)
 		}
+		recordRename(srcPath, dstPath)
 	}
 
 	// Replace package name with "app" in all Go files
@@ -790,7 +1301,7 @@ func applySimpleMode(config *Config) error {
 
 // replacePackageNames replaces package names and import paths in Go files
 func replacePackageNames(dir, oldPkg, newPkg, oldModule, newModule string) error {
-	tap.Message(fmt.Sprintf("📝 Updating package names from %s to %s...\n", oldPkg, newPkg))
+	showMessage(fmt.Sprintf("📝 Updating package names from %s to %s...\n", oldPkg, newPkg))
 
 	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -819,8 +1330,8 @@ func replacePackageNames(dir, oldPkg, newPkg, oldModule, newModule string) error
 			fileContent = regexp.MustCompile(`(?m)^\s+ModuleName\s*=\s*"`+regexp.QuoteMeta(oldPkg)+`"$`).ReplaceAllString(fileContent, "\tModuleName    = \""+newPkg+"\"")
 		}
 
-		// Replace import paths
-		fileContent = strings.ReplaceAll(fileContent, oldModule, newModule)
+		// Replace import paths, oldModule/app doesn't match oldModule/apps
+		fileContent = regexp.MustCompile(regexp.QuoteMeta(oldModule)+`([^A-Za-z0-9._~-]|$)`).ReplaceAllString(fileContent, newModule+"$1")
 
 		if err := os.WriteFile(path, []byte(fileContent), 0644); err != nil {
 			return err
@@ -844,7 +1355,7 @@ func replaceInFile(filePath, oldString, newString string) error {
 
 // handleFeatureFolders includes or excludes folders based on selected features
 func handleFeatureFolders(modulePath string, features []Feature) error {
-	tap.Message("📝 Handling feature-based folders...")
+	showMessage("📝 Handling feature-based folders...")
 
 	// Check which features are selected
 	hasConfig := false
@@ -869,7 +1380,7 @@ func handleFeatureFolders(modulePath string, features []Feature) error {
 			if err := os.RemoveAll(configPath); err != nil {
 				return fmt.Errorf("failed to remove config folder: %w", err)
 			}
-			tap.Message("⏭️ Removed config folder (specific config not selected)")
+			showMessage("⏭️ Removed config folder (specific config not selected)")
 		}
 	}
 
@@ -881,14 +1392,14 @@ func handleFeatureFolders(modulePath string, features []Feature) error {
 			if err := os.RemoveAll(servicePath); err != nil {
 				return fmt.Errorf("failed to remove service folder: %w", err)
 			}
-			tap.Message("⏭️ Removed service folder (database repository not selected)")
+			showMessage("⏭️ Removed service folder (database repository not selected)")
 		}
 
 		if _, err := os.Stat(repositoryPath); err == nil {
 			if err := os.RemoveAll(repositoryPath); err != nil {
 				return fmt.Errorf("failed to remove repository folder: %w", err)
 			}
-			tap.Message("⏭️ Removed repository folder (database repository not selected)")
+			showMessage("⏭️ Removed repository folder (database repository not selected)")
 		}
 	}
 
@@ -898,17 +1409,17 @@ func handleFeatureFolders(modulePath string, features []Feature) error {
 			if err := os.RemoveAll(handlerPath); err != nil {
 				return fmt.Errorf("failed to remove handler folder: %w", err)
 			}
-			tap.Message("⏭️ Removed handler folder (http request handler not selected)")
+			showMessage("⏭️ Removed handler folder (http request handler not selected)")
 		}
 	}
 
-	tap.Message("✅ Feature-based folders updated")
+	showMessage("✅ Feature-based folders updated")
 	return nil
 }
 
 // updatePackagesGo updates webcore/deps/packages.go with the correct module import
 func updatePackagesGo(config *Config) error {
-	tap.Message("📝 Updating webcore/deps/packages.go...")
+	showMessage("📝 Updating webcore/deps/packages.go...")
 
 	packagesPath := filepath.Join(config.ProjectDir, "webcore", "deps", "packages.go")
 	content, err := os.ReadFile(packagesPath)
@@ -921,8 +1432,9 @@ func updatePackagesGo(config *Config) error {
 	var moduleCall string
 
 	if config.ProjectMode == "mono-repo" {
-		importLine = fmt.Sprintf("\t%s \"%s\"", config.FolderName, config.ModuleModName)
-		moduleCall = fmt.Sprintf("\t%s.NewModule(),", config.FolderName)
+		importAlias := config.PackageName
+		importLine = fmt.Sprintf("\t%s \"%s\"", importAlias, config.ModuleModName)
+		moduleCall = fmt.Sprintf("\t%s.NewModule(),", importAlias)
 	} else {
 		importLine = "\tapp \"github.com/semanggilab/project1/app\""
 		if config.ModuleName != defaultModuleName {
@@ -952,7 +1464,7 @@ func updatePackagesGo(config *Config) error {
 		return err
 	}
 
-	tap.Message("✅ Updated webcore/deps/packages.go")
+	showMessage("✅ Updated webcore/deps/packages.go")
 	return nil
 }
 
@@ -963,19 +1475,19 @@ func cleanupDummyFolder(projectDir string) error {
 		if err := os.RemoveAll(dummyPath); err != nil {
 			return fmt.Errorf("failed to remove dummy folder: %w", err)
 		}
-		tap.Message("✅ Removed modules/dummy folder")
+		showMessage("✅ Removed modules/dummy folder")
 	}
 	return nil
 }
 
 // updateGoWork updates the go.work file in the project directory
-func updateGoWork(config *Config) error {
+func updateGoWork(ctx context.Context, config *Config) error {
 	// Only update go.work for mono-repo mode
 	if config.ProjectMode != "mono-repo" {
 		return nil
 	}
 
-	tap.Message("📝 Updating go.work file...")
+	showMessage("📝 Updating go.work file...")
 
 	goWorkPath := filepath.Join(config.ProjectDir, "go.work")
 	content, err := os.ReadFile(goWorkPath)
@@ -992,13 +1504,13 @@ func updateGoWork(config *Config) error {
 		if trimmedLine == "./modules/dummy" {
 			lines[i] = fmt.Sprintf("\t./modules/%s", config.FolderName)
 			updated = true
-			tap.Message(fmt.Sprintf("✅ Replaced ./modules/dummy with ./modules/%s\n", config.FolderName))
+			showMessage(fmt.Sprintf("✅ Replaced ./modules/dummy with ./modules/%s\n", config.FolderName))
 			break
 		}
 	}
 
 	if !updated {
-		tap.Message("⚠️ No ./modules/dummy line found in go.work, skipping update")
+		warn("go-work", "No ./modules/dummy line found in go.work, skipping update")
 		return nil
 	}
 
@@ -1009,39 +1521,22 @@ func updateGoWork(config *Config) error {
 	}
 
 	// Run go work sync
-	sp := tap.NewSpinner(tap.SpinnerOptions{Indicator: "dots"})
+	sp := newSpinner()
 	sp.Start("Running go work sync...")
 
-	cmd := exec.Command("go", "work", "sync")
+	cmd := exec.CommandContext(ctx, "go", "work", "sync")
 	cmd.Dir = config.ProjectDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := runExternal(ctx, cmd); err != nil {
+		if ctx.Err() != nil {
+			sp.Stop("❌ go work sync interrupted", 1)
+			return ctx.Err()
+		}
 		sp.Stop("⚠️ go work sync completed with warnings", 0)
-		tap.Message(fmt.Sprintf("⚠️ Warning: %v\n", err))
+		warn("go-work", fmt.Sprintf("go work sync failed: %v", err))
 		return nil
 	}
 
 	sp.Stop("✅ go.work updated and synced", 0)
 	return nil
 }
-
-// initGit initializes git repository
-func initGit(ctx context.Context, projectDir string) error {
-	sp := tap.NewSpinner(tap.SpinnerOptions{Indicator: "dots"})
-	sp.Start("Initializing git repository...")
-
-	cmd := exec.Command("git", "init")
-	cmd.Dir = projectDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		sp.Stop("❌ Failed to initialize git", 1)
-		return fmt.Errorf("git init failed: %w", err)
-	}
-
-	sp.Stop("✅ Git repository initialized", 0)
-	return nil
-}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClonedTemplateRemove(t *testing.T) {
+	t.Run("created directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "project")
+		if err := os.MkdirAll(filepath.Join(dir, "webcore"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := (&clonedTemplate{Dir: dir, Created: true}).remove(); err != nil {
+			t.Fatal(err)
+		}
+		if fileExists(dir) {
+			t.Error("the directory created by the clone wasn't removed")
+		}
+	})
+
+	t.Run("existing directory", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"webcore/go.mod", "README.md", "notes.txt"} {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := (&clonedTemplate{Dir: dir, Entries: []string{"webcore", "README.md"}}).remove(); err != nil {
+			t.Fatal(err)
+		}
+		if !fileExists(filepath.Join(dir, "notes.txt")) {
+			t.Error("a file of the user was removed")
+		}
+		if fileExists(filepath.Join(dir, "webcore")) || fileExists(filepath.Join(dir, "README.md")) {
+			t.Error("the entries of the template weren't removed")
+		}
+	})
+
+	t.Run("working directory", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "go.work"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		t.Chdir(dir)
+		if err := (&clonedTemplate{Dir: ".", Created: true, Entries: []string{"go.work"}}).remove(); err != nil {
+			t.Fatal(err)
+		}
+		if !fileExists(dir) {
+			t.Fatal("the working directory was removed")
+		}
+		if fileExists(filepath.Join(dir, "go.work")) {
+			t.Error("the entries of the template weren't removed")
+		}
+	})
+}
+
+func TestValidateModuleName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"github.com/acme/orders", true},
+		{"github.com/acme/orders/v2", true},
+		{"github.com/acme/orders-svc.v2", true},
+		{"example.com/a_b~c", true},
+		{"", false},
+		{"orders", false},
+		{"github.com/Acme/orders", false},
+		{"github.com/acme/Orders_Svc", false},
+		{"github.com/acme/orders/", false},
+		{"github.com//orders", false},
+		{"/github.com/acme/orders", false},
+		{"github.com/acme/orders svc", false},
+		{"github.com/acme/../orders", false},
+		{"-acme.com/orders", false},
+		{"github.com/acme/orders.", false},
+	}
+	for _, tt := range tests {
+		err := validateModuleName(tt.name)
+		if tt.valid && err != nil {
+			t.Errorf("validateModuleName(%q) = %v, want valid", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("validateModuleName(%q) is valid, want an error", tt.name)
+		}
+	}
+}
+
+func TestReplacePackageNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{
+			name:    "package and imports",
+			file:    "handler/handler.go",
+			content: "package app\n\nimport (\n\t\"github.com/acme/orders/app/service\"\n\tx \"github.com/acme/orders/app\"\n)\n",
+			want:    "package billing\n\nimport (\n\t\"github.com/acme/orders/modules/billing/service\"\n\tx \"github.com/acme/orders/modules/billing\"\n)\n",
+		},
+		{
+			name:    "module name",
+			file:    "module.go",
+			content: "package app\n\nconst (\n\tModuleName = \"app\"\n)\n",
+			want:    "package billing\n\nconst (\n\tModuleName    = \"billing\"\n)\n",
+		},
+		{
+			name:    "other packages",
+			file:    "apps.go",
+			content: "package application\n\nimport \"github.com/acme/orders/apps\"\n\nvar app = \"github.com/acme/orders/app.v2\"\n",
+			want:    "package application\n\nimport \"github.com/acme/orders/apps\"\n\nvar app = \"github.com/acme/orders/app.v2\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := writeTestFile(t, dir, tt.file, tt.content)
+			if err := replacePackageNames(dir, "app", "billing", "github.com/acme/orders/app", "github.com/acme/orders/modules/billing"); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("%s = %q, want %q", tt.file, content, tt.want)
+			}
+		})
+	}
+}
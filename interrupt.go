@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// interruptSignals are the signals that cancel the installer
+var interruptSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// resumeHint is printed when the installer is cancelled after the checkpoint was written
+var resumeHint string
+
+// newInterruptContext returns a context that is cancelled on Ctrl-C or SIGTERM.
+// The prompts run on the installer's own terminal (see promptTerminal), so this is the only interrupt handler.
+func newInterruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), interruptSignals...)
+}
+
+// exitCancelled stops the installer after a cancelled prompt or an interrupt
+func exitCancelled() {
+	finishReport(nil, context.Canceled, true)
+
+	message := "❌ Installation cancelled\n"
+	if resumeHint != "" {
+		message += resumeHint + "\n"
+	}
+	showOutro(message)
+	exit(exitCodeInterrupted)
+}
+
+// exit restores the terminal of a prompt that is still showing and exits with code, every exit goes through it
+func exit(code int) {
+	closePromptTerminal()
+	os.Exit(code)
+}
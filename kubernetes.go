@@ -0,0 +1,478 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yarlson/tap"
+	"gopkg.in/yaml.v3"
+)
+
+// Kubernetes output formats
+const (
+	k8sManifests = "manifests"
+	k8sHelm      = "helm"
+	k8sNone      = "none"
+)
+
+// k8sDir is the directory of the generated manifests and chart, relative to the project directory
+var k8sDir = filepath.Join("deploy", "kubernetes")
+
+// defaultHTTPPort is the container port when config.yaml doesn't set one
+const defaultHTTPPort = 8080
+
+// invalidNameChars are the characters that aren't allowed in a Kubernetes object name
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// credentialKeys are the config.yaml keys that are moved from the ConfigMap to the credentials Secret
+var credentialKeys = map[string]bool{"password": true, "secret": true, "token": true, "api_key": true, "apikey": true, "client_secret": true}
+
+// k8sEnvVar is an environment variable of the container
+type k8sEnvVar struct {
+	Name  string
+	Value string
+}
+
+// helmTemplates are the templates of the generated chart, they only depend on the values
+var helmTemplates = map[string]string{
+	"configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-config
+data:
+  config.yaml: |
+    {{- .Values.config | nindent 4 }}
+`,
+	"secret.yaml": `apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Release.Name }}-access
+type: Opaque
+stringData:
+  access.yaml: |
+    {{- required "pass the real access.yaml with --set-file access=access.yaml" .Values.access | nindent 4 }}
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Release.Name }}-credentials
+type: Opaque
+stringData:
+  {{- range $name, $value := .Values.credentials }}
+  {{ $name }}: {{ $value | quote }}
+  {{- end }}
+`,
+	"service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  type: {{ .Values.service.type }}
+  selector:
+    app.kubernetes.io/name: {{ .Chart.Name }}
+    app.kubernetes.io/instance: {{ .Release.Name }}
+  ports:
+    - name: http
+      port: {{ .Values.service.port }}
+      targetPort: http
+`,
+	"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    app.kubernetes.io/name: {{ .Chart.Name }}
+    app.kubernetes.io/instance: {{ .Release.Name }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{ .Chart.Name }}
+      app.kubernetes.io/instance: {{ .Release.Name }}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: {{ .Chart.Name }}
+        app.kubernetes.io/instance: {{ .Release.Name }}
+      annotations:
+        checksum/config: {{ .Values.config | sha256sum }}
+        checksum/access: {{ .Values.access | sha256sum }}
+        checksum/credentials: {{ .Values.credentials | toJson | sha256sum }}
+    spec:
+      securityContext:
+        runAsNonRoot: true
+      containers:
+        - name: {{ .Chart.Name }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          ports:
+            - name: http
+              containerPort: {{ .Values.service.port }}
+          env:
+            {{- range $name, $value := .Values.env }}
+            - name: {{ $name }}
+              value: {{ $value | quote }}
+            {{- end }}
+          envFrom:
+            - secretRef:
+                name: {{ .Release.Name }}-credentials
+          volumeMounts:
+            - name: config
+              mountPath: /app/config.yaml
+              subPath: config.yaml
+            - name: access
+              mountPath: /app/access.yaml
+              subPath: access.yaml
+      volumes:
+        - name: config
+          configMap:
+            name: {{ .Release.Name }}-config
+        - name: access
+          secret:
+            secretName: {{ .Release.Name }}-access
+`,
+}
+
+// parseKubernetesFormat validates a Kubernetes output format, an empty format means none
+func parseKubernetesFormat(value string) (string, error) {
+	switch value {
+	case k8sManifests, k8sHelm:
+		return value, nil
+	case "", k8sNone:
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown Kubernetes format %q, expected manifests, helm or none", value)
+}
+
+// selectKubernetesFormat asks for the Kubernetes output format, preselecting current
+func selectKubernetesFormat(ctx context.Context, current string) string {
+	initialValue := k8sNone
+	if current != "" {
+		initialValue = current
+	}
+	format := promptSelect(ctx, tap.SelectOptions[string]{
+		Message: withSource("Generate Kubernetes deployment files?", "kubernetes"),
+		Options: []tap.SelectOption[string]{
+			{Label: "No Kubernetes files", Value: k8sNone},
+			{Label: "Raw manifests (deploy/kubernetes)", Value: k8sManifests},
+			{Label: "Helm chart (deploy/kubernetes/<name>)", Value: k8sHelm},
+		},
+		InitialValue: &initialValue,
+	})
+
+	showMessage(fmt.Sprintf("✅ Kubernetes: %s\n", format))
+	if format == k8sNone {
+		return ""
+	}
+	return format
+}
+
+// kubernetesDonePath returns the file that exists once the Kubernetes files are generated
+func kubernetesDonePath(config *Config) string {
+	if config.Kubernetes == k8sHelm {
+		return filepath.Join(config.ProjectDir, k8sDir, kubernetesAppName(config), "Chart.yaml")
+	}
+	return filepath.Join(config.ProjectDir, k8sDir, "deployment.yaml")
+}
+
+// generateKubernetes writes the Deployment, Service, ConfigMap and Secret as raw manifests or as a Helm chart.
+// The ConfigMap holds the enabled sections of config.yaml.example without credentials, the Secret only the names
+// of the credentials, so no generated file contains a secret. Existing files are only overwritten when confirmed.
+func generateKubernetes(ctx context.Context, config *Config) error {
+	files, err := kubernetesFiles(config)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for _, name := range sortedKeys(files) {
+		if confirmOverwrite(ctx, filepath.Join(config.ProjectDir, k8sDir, name)) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	sp := newSpinner()
+	sp.Start("Generating Kubernetes files...")
+
+	for _, name := range names {
+		filePath := filepath.Join(config.ProjectDir, k8sDir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			sp.Stop("❌ Failed to write Kubernetes files", 1)
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(filePath), err)
+		}
+		if err := os.WriteFile(filePath, files[name], 0644); err != nil {
+			sp.Stop("❌ Failed to write Kubernetes files", 1)
+			return fmt.Errorf("failed to write %s: %w", filePath, err)
+		}
+	}
+
+	sp.Stop(fmt.Sprintf("✅ Kubernetes %s generated in %s", config.Kubernetes, filepath.Join(config.ProjectDir, k8sDir)), 0)
+	return nil
+}
+
+// kubernetesFiles returns the generated files by path relative to k8sDir
+func kubernetesFiles(config *Config) (map[string][]byte, error) {
+	settings, err := readConfigYAML(config.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	configData, credentialNames, err := kubernetesConfigYAML(config.ProjectDir, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	name := kubernetesAppName(config)
+	port := httpPort(settings)
+	env := kubernetesEnv(config, settings)
+	header := "# Generated by webcore-go-install\n"
+	credentials := make(map[string]string)
+	for _, credentialName := range credentialNames {
+		credentials[credentialName] = ""
+	}
+	exampleCredential := "NAME"
+	if len(credentialNames) > 0 {
+		exampleCredential = credentialNames[0]
+	}
+
+	files := make(map[string][]byte)
+	if config.Kubernetes == k8sHelm {
+		envValues := make(map[string]string)
+		for _, v := range env {
+			envValues[v.Name] = v.Value
+		}
+		chart := map[string]any{
+			"apiVersion":  "v2",
+			"name":        name,
+			"description": fmt.Sprintf("Helm chart of %s", config.ModuleName),
+			"type":        "application",
+			"version":     "0.1.0",
+			"appVersion":  "0.1.0",
+		}
+		values := map[string]any{
+			"replicaCount": 1,
+			"image":        map[string]any{"repository": name, "tag": "latest", "pullPolicy": "IfNotPresent"},
+			"service":      map[string]any{"type": "ClusterIP", "port": port},
+			"env":          envValues,
+			"config":       configData,
+			"access":       "",
+			"credentials":  credentials,
+		}
+
+		if files[path.Join(name, "Chart.yaml")], err = marshalYAML(header, chart); err != nil {
+			return nil, err
+		}
+		valuesHeader := header +
+			"# access and credentials are empty, pass them at install time, e.g.:\n" +
+			fmt.Sprintf("# --set-file access=access.yaml --set credentials.%s=<value>\n", exampleCredential)
+		if files[path.Join(name, "values.yaml")], err = marshalYAML(valuesHeader, values); err != nil {
+			return nil, err
+		}
+		for templateName, content := range helmTemplates {
+			files[path.Join(name, "templates", templateName)] = []byte(content)
+		}
+		return files, nil
+	}
+
+	labels := map[string]string{"app.kubernetes.io/name": name}
+	containerEnv := make([]map[string]string, len(env))
+	for i, v := range env {
+		containerEnv[i] = map[string]string{"name": v.Name, "value": v.Value}
+	}
+
+	objects := map[string]map[string]any{
+		"configmap.yaml": {
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]any{"name": name + "-config"},
+			"data":       map[string]string{"config.yaml": configData},
+		},
+		"secret.yaml": {
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]any{"name": name + "-credentials"},
+			"type":       "Opaque",
+			"stringData": credentials,
+		},
+		"service.yaml": {
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]any{"name": name},
+			"spec": map[string]any{
+				"selector": labels,
+				"ports":    []map[string]any{{"name": "http", "port": port, "targetPort": "http"}},
+			},
+		},
+		"deployment.yaml": {
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]any{"name": name, "labels": labels},
+			"spec": map[string]any{
+				"replicas": 1,
+				"selector": map[string]any{"matchLabels": labels},
+				"template": map[string]any{
+					"metadata": map[string]any{"labels": labels},
+					"spec": map[string]any{
+						"securityContext": map[string]any{"runAsNonRoot": true},
+						"containers": []map[string]any{{
+							"name":  name,
+							"image": name + ":latest",
+							"ports": []map[string]any{{"name": "http", "containerPort": port}},
+							"env":   containerEnv,
+							"envFrom": []map[string]any{
+								{"secretRef": map[string]string{"name": name + "-credentials"}},
+							},
+							"volumeMounts": []map[string]string{
+								{"name": "config", "mountPath": "/app/config.yaml", "subPath": "config.yaml"},
+								{"name": "access", "mountPath": "/app/access.yaml", "subPath": "access.yaml"},
+							},
+						}},
+						"volumes": []map[string]any{
+							{"name": "config", "configMap": map[string]string{"name": name + "-config"}},
+							{"name": "access", "secret": map[string]string{"secretName": name + "-access"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	secretHeader := header +
+		"# The credentials of config.yaml as environment variables, fill them in before applying or create the secret with:\n" +
+		fmt.Sprintf("# kubectl create secret generic %s-credentials --from-literal=%s=<value>\n", name, exampleCredential) +
+		"# The deployment also mounts access.yaml, create its secret from the real file:\n" +
+		fmt.Sprintf("# kubectl create secret generic %s-access --from-file=access.yaml\n", name)
+	for fileName, object := range objects {
+		fileHeader := header
+		if fileName == "secret.yaml" {
+			fileHeader = secretHeader
+		}
+		if files[fileName], err = marshalYAML(fileHeader, object); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// kubernetesAppName derives the name of the Kubernetes objects from the last element of the module name
+func kubernetesAppName(config *Config) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(path.Base(config.ModuleName)), "-")
+	name = strings.Trim(name, "-")
+	if name == "" || name == "." {
+		return "webcore"
+	}
+	return name
+}
+
+// kubernetesConfigYAML returns config.yaml.example without its comments and credentials for the ConfigMap,
+// with only the sections enabled in config.yaml. It also returns the environment variable names of the
+// removed credentials, e.g. DATABASE_PASSWORD, which go to the Secret.
+func kubernetesConfigYAML(projectDir string, settings map[string]any) (string, []string, error) {
+	content, err := os.ReadFile(filepath.Join(projectDir, "config.yaml.example"))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read config.yaml.example: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return "", nil, fmt.Errorf("failed to parse config.yaml.example: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return "", nil, nil
+	}
+
+	// Sections commented in config.yaml belong to libraries that aren't selected
+	root := doc.Content[0]
+	if settings != nil {
+		enabled := make([]*yaml.Node, 0, len(root.Content))
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if _, ok := settings[root.Content[i].Value]; ok {
+				enabled = append(enabled, root.Content[i], root.Content[i+1])
+			}
+		}
+		root.Content = enabled
+	}
+
+	names := make([]string, 0)
+	removeCredentials(root, nil, &names)
+	clearComments(&doc)
+
+	out, err := marshalYAML("", &doc)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(out), names, nil
+}
+
+// removeCredentials removes the credential keys below a mapping node and collects their environment variable names
+func removeCredentials(node *yaml.Node, keyPath []string, names *[]string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	content := make([]*yaml.Node, 0, len(node.Content))
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		childPath := append(append([]string{}, keyPath...), key)
+		if credentialKeys[strings.ToLower(key)] && value.Kind == yaml.ScalarNode && len(keyPath) > 0 {
+			*names = append(*names, strings.ToUpper(strings.Join(childPath, "_")))
+			continue
+		}
+		removeCredentials(value, childPath, names)
+		content = append(content, node.Content[i], value)
+	}
+	node.Content = content
+}
+
+// clearComments removes the comments of a YAML node and its children
+func clearComments(node *yaml.Node) {
+	node.HeadComment, node.LineComment, node.FootComment = "", "", ""
+	for _, child := range node.Content {
+		clearComments(child)
+	}
+}
+
+// httpPort returns the port the server listens on from the server or app section of config.yaml
+func httpPort(settings map[string]any) int {
+	for _, section := range []string{"server", "app", "http"} {
+		values, _ := settings[section].(map[string]any)
+		if port, err := strconv.Atoi(firstSetting(values, "port")); err == nil {
+			return port
+		}
+	}
+	return defaultHTTPPort
+}
+
+// kubernetesEnv returns the env overrides of the connection settings of the selected libraries,
+// with the hosts and ports of config.yaml. The overrides are named after the section, so services that share
+// a section get one set of overrides, for the first of them.
+func kubernetesEnv(config *Config, settings map[string]any) []k8sEnvVar {
+	env := make([]k8sEnvVar, 0)
+	owners := make(map[string]string)
+	for _, service := range selectedBackingServices(config.SelectedLibraries) {
+		if service.Name == "pubsub" {
+			continue
+		}
+		if owner, shared := owners[service.Section]; shared {
+			warn("kubernetes", fmt.Sprintf("%s and %s both use the config.yaml %s section, the Deployment has one set of %s_* overrides for them", owner, service.Name, service.Section, strings.ToUpper(service.Section)))
+			continue
+		}
+		owners[service.Section] = service.Name
+
+		section, _ := settings[service.Section].(map[string]any)
+		for _, key := range []string{"host", "port", "address", "addr", "brokers"} {
+			if value := firstSetting(section, key); value != "" {
+				env = append(env, k8sEnvVar{Name: settingEnvName(service.Section, key), Value: value})
+			}
+		}
+	}
+	return env
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockFileName    = "webcore-install.lock"
+	lockFileVersion = 1
+)
+
+// templateCommit is the commit of the downloaded template, empty when it isn't known
+var templateCommit string
+
+// installLock is the record of an installation, written to the project root
+type installLock struct {
+	Version        int       `json:"version"`
+	Template       string    `json:"template"`
+	TemplateCommit string    `json:"template_commit,omitempty"`
+	InstalledAt    time.Time `json:"installed_at"`
+	Config         *Config   `json:"config"`
+}
+
+// writeLockFile writes the lock file for the given configuration into the project directory
+func writeLockFile(config *Config) error {
+	lock := installLock{
+		Version:        lockFileVersion,
+		Template:       templateSource,
+		TemplateCommit: templateCommit,
+		InstalledAt:    time.Now().UTC(),
+		Config:         config,
+	}
+
+	return saveLockFile(filepath.Join(config.ProjectDir, lockFileName), &lock)
+}
+
+// saveLockFile writes the lock as indented JSON
+func saveLockFile(lockPath string, lock *installLock) error {
+	content, err := lockFileContent(lock)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(lockPath, content, 0644)
+}
+
+// lockFileContent encodes the lock as indented JSON
+func lockFileContent(lock *installLock) ([]byte, error) {
+	content, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// readLockFile reads and parses a lock file, a lock file without configuration is invalid
+func readLockFile(lockPath string) (*installLock, error) {
+	content, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	hint := fmt.Sprintf("fix or remove %s", lockPath)
+	lock := &installLock{}
+	if err := json.Unmarshal(content, lock); err != nil {
+		return nil, invalidInputError(hint, fmt.Errorf("failed to parse %s: %w", lockPath, err))
+	}
+	if lock.Config == nil {
+		return nil, invalidInputError(hint, fmt.Errorf("%s contains no configuration", lockPath))
+	}
+	return lock, nil
+}
+
+// findProjectRoot walks up from dir looking for a lock file.
+// It returns the project root and the lock file path, or empty strings if none is found.
+func findProjectRoot(dir string) (string, string) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", ""
+	}
+
+	for {
+		lockPath := filepath.Join(dir, lockFileName)
+		if _, err := os.Stat(lockPath); err == nil {
+			return dir, lockPath
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+const checkpointFileName = "webcore-install.checkpoint"
+
+// checkpoint records the progress of an installation so it can be resumed after a failure
+type checkpoint struct {
+	Config         *Config   `json:"config"`
+	TemplateCommit string    `json:"template_commit,omitempty"`
+	CompletedSteps []string  `json:"completed_steps"`
+	FailedStep     string    `json:"failed_step,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	resume bool // whether finished steps are skipped
+}
+
+// newCheckpoint creates an empty checkpoint for the given configuration
+func newCheckpoint(config *Config) *checkpoint {
+	return &checkpoint{Config: config, TemplateCommit: templateCommit, CompletedSteps: []string{}}
+}
+
+// readCheckpoint reads the checkpoint file of the project directory
+func readCheckpoint(projectDir string) (*checkpoint, error) {
+	checkpointPath := filepath.Join(projectDir, checkpointFileName)
+	content, err := os.ReadFile(checkpointPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &checkpoint{}
+	if err := json.Unmarshal(content, cp); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", checkpointPath, err)
+	}
+	if cp.Config == nil {
+		return nil, fmt.Errorf("%s contains no configuration", checkpointPath)
+	}
+	cp.Config.ProjectDir = projectDir
+	return cp, nil
+}
+
+// save writes the checkpoint file into the project directory
+func (cp *checkpoint) save() error {
+	cp.UpdatedAt = time.Now().UTC()
+
+	content, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	checkpointPath := filepath.Join(cp.Config.ProjectDir, checkpointFileName)
+	return os.WriteFile(checkpointPath, append(content, '\n'), 0644)
+}
+
+// remove deletes the checkpoint file after a successful installation
+func (cp *checkpoint) remove() error {
+	err := os.Remove(filepath.Join(cp.Config.ProjectDir, checkpointFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// isCompleted checks if the step finished in an earlier run
+func (cp *checkpoint) isCompleted(name string) bool {
+	for _, completed := range cp.CompletedSteps {
+		if completed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// markCompleted records a finished step and saves the checkpoint
+func (cp *checkpoint) markCompleted(name string) error {
+	if !cp.isCompleted(name) {
+		cp.CompletedSteps = append(cp.CompletedSteps, name)
+	}
+	if cp.FailedStep == name {
+		cp.FailedStep = ""
+		cp.Error = ""
+	}
+	return cp.save()
+}
+
+// markFailed records the step that failed and saves the checkpoint
+func (cp *checkpoint) markFailed(name string, stepErr error) error {
+	cp.FailedStep = name
+	cp.Error = stepErr.Error()
+	return cp.save()
+}
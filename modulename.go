@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// inferModuleName derives the default module name of the project directory from a go.mod or go.work
+// in a parent directory, or from the origin remote of the git repository it is in.
+// It returns the module name and where it came from, or empty strings when nothing was found.
+func inferModuleName(projectDir string) (string, string) {
+	abs, err := filepath.Abs(projectDir)
+	if err != nil {
+		return "", ""
+	}
+
+	// The project directory holds the template's own go.work, so the search starts at its parent
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		if root, source := moduleRootOf(dir); root != "" {
+			return joinModulePath(root, dir, abs), source
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	if root, topLevel := gitRemoteModuleRoot(abs); root != "" {
+		return joinModulePath(root, topLevel, abs), "git remote origin"
+	}
+	return "", ""
+}
+
+// moduleRootOf returns the module path of dir from its go.mod, or the common root of the modules of its go.work
+func moduleRootOf(dir string) (string, string) {
+	goModPath := filepath.Join(dir, "go.mod")
+	if content, err := os.ReadFile(goModPath); err == nil {
+		if modulePath := modfile.ModulePath(content); modulePath != "" {
+			return modulePath, "parent go.mod"
+		}
+	}
+
+	goWorkPath := filepath.Join(dir, "go.work")
+	content, err := os.ReadFile(goWorkPath)
+	if err != nil {
+		return "", ""
+	}
+	work, err := modfile.ParseWork(goWorkPath, content, nil)
+	if err != nil {
+		return "", ""
+	}
+	for _, use := range work.Use {
+		modContent, err := os.ReadFile(filepath.Join(dir, use.Path, "go.mod"))
+		if err != nil {
+			continue
+		}
+		modulePath := modfile.ModulePath(modContent)
+		if modulePath == "" {
+			continue
+		}
+		// A module in a sub-directory of the workspace usually has the directory as suffix of its path
+		usePath := path.Clean(filepath.ToSlash(use.Path))
+		if usePath == "." {
+			return modulePath, "parent go.work"
+		}
+		if root, ok := strings.CutSuffix(modulePath, "/"+usePath); ok {
+			return root, "parent go.work"
+		}
+		return path.Dir(modulePath), "parent go.work"
+	}
+	return "", ""
+}
+
+// gitRemoteModuleRoot returns the module path of the origin remote of the git repository dir is in,
+// together with the top level directory of the repository
+func gitRemoteModuleRoot(dir string) (string, string) {
+	// The project directory may not exist yet, git needs an existing directory
+	for !fileExists(dir) && filepath.Dir(dir) != dir {
+		dir = filepath.Dir(dir)
+	}
+
+	topLevel, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", ""
+	}
+	remote, err := exec.Command("git", "-C", dir, "config", "--get", "remote.origin.url").Output()
+	if err != nil {
+		return "", ""
+	}
+
+	root := remoteModulePath(strings.TrimSpace(string(remote)))
+	if root == "" {
+		return "", ""
+	}
+	return root, strings.TrimSpace(string(topLevel))
+}
+
+// remoteModulePath converts a git remote URL to a module path,
+// e.g. git@github.com:ourorg/billing.git and https://github.com/ourorg/billing.git become github.com/ourorg/billing
+func remoteModulePath(url string) string {
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+
+	if scheme, rest, ok := strings.Cut(url, "://"); ok {
+		if scheme == "file" {
+			return ""
+		}
+		url = rest
+	} else if host, repoPath, ok := strings.Cut(url, ":"); ok && !strings.Contains(host, "/") {
+		// scp-like syntax: [user@]host:org/repo
+		url = host + "/" + repoPath
+	} else {
+		return ""
+	}
+
+	// Drop the user and the port of the host
+	if _, rest, ok := strings.Cut(url, "@"); ok {
+		url = rest
+	}
+	host, repoPath, _ := strings.Cut(url, "/")
+	host, _, _ = strings.Cut(host, ":")
+
+	modulePath := strings.ToLower(path.Join(host, repoPath))
+	if validateModuleName(modulePath) != nil {
+		return ""
+	}
+	return modulePath
+}
+
+// joinModulePath appends the path of dir relative to root to the module path of root
+func joinModulePath(modulePath, root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return modulePath
+	}
+	return path.Join(modulePath, strings.ToLower(filepath.ToSlash(rel)))
+}
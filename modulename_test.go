@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestRemoteModulePath(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string // module path, none when the remote can't be converted
+	}{
+		{"https://github.com/ourorg/billing.git", "github.com/ourorg/billing"},
+		{"https://github.com/ourorg/billing", "github.com/ourorg/billing"},
+		{"https://github.com/ourorg/billing/", "github.com/ourorg/billing"},
+		{"https://user@gitlab.example.com:8443/group/sub/billing.git", "gitlab.example.com/group/sub/billing"},
+		{"ssh://git@github.com:22/ourorg/billing.git", "github.com/ourorg/billing"},
+		{"git@github.com:ourorg/billing.git", "github.com/ourorg/billing"},
+		{"github.com:OurOrg/Billing", "github.com/ourorg/billing"},
+		{"file:///srv/git/billing.git", ""},
+		{"/srv/git/billing.git", ""},
+		{"../billing", ""},
+		{"", ""},
+		{"https://localhost/billing.git", ""},
+	}
+	for _, tt := range tests {
+		if got := remoteModulePath(tt.url); got != tt.want {
+			t.Errorf("remoteModulePath(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
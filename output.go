@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yarlson/tap"
+)
+
+// Output modes
+const (
+	outputPretty = "pretty" // tap UI with spinners and colors
+	outputPlain  = "plain"  // one timestamped line per event, no ANSI and no spinner
+	outputJSON   = "json"   // newline-delimited JSON events
+)
+
+// outputMode is the active output mode, see detectOutputMode
+var outputMode = outputPretty
+
+// outputMu keeps plain and JSON lines from interleaving
+var outputMu sync.Mutex
+
+// outputEvent is a single line of JSON output
+type outputEvent struct {
+	Time     time.Time  `json:"time"`
+	Event    string     `json:"event"` // intro, message, outro, spinner, step, command or table
+	Level    string     `json:"level,omitempty"`
+	Message  string     `json:"message,omitempty"`
+	Step     string     `json:"step,omitempty"`
+	Status   string     `json:"status,omitempty"`
+	Duration string     `json:"duration,omitempty"`
+	Command  string     `json:"command,omitempty"`
+	Dir      string     `json:"dir,omitempty"`
+	ExitCode *int       `json:"exit_code,omitempty"`
+	Stdout   string     `json:"stdout,omitempty"`
+	Stderr   string     `json:"stderr,omitempty"`
+	Headers  []string   `json:"headers,omitempty"`
+	Rows     [][]string `json:"rows,omitempty"`
+}
+
+// detectOutputMode selects plain output when stdout isn't a terminal or NO_COLOR is set
+func detectOutputMode() string {
+	if os.Getenv("NO_COLOR") != "" {
+		return outputPlain
+	}
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return outputPlain
+	}
+	return outputPretty
+}
+
+// parseOutputMode validates the --output value, an empty value keeps the detected mode
+func parseOutputMode(value string) (string, error) {
+	switch value {
+	case "":
+		return detectOutputMode(), nil
+	case outputPretty, outputPlain, outputJSON:
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown output mode %q, expected pretty, plain or json", value)
+}
+
+// messageLevels maps the leading symbol of a message to its level in plain and JSON output
+var messageLevels = []struct {
+	Symbol string
+	Level  string
+}{
+	{"❌", "error"},
+	{"⚠️", "warning"},
+	{"✅", "info"},
+	{"⏭️", "info"},
+	{"📝", "info"},
+	{"⏱️", "info"},
+	{"♻️", "info"},
+	{"🪝", "info"},
+}
+
+// splitMessage strips the leading symbol and surrounding whitespace of a message and returns its level
+func splitMessage(message string) (string, string) {
+	message = strings.TrimSpace(message)
+	for _, m := range messageLevels {
+		if strings.HasPrefix(message, m.Symbol) {
+			return strings.TrimSpace(strings.TrimPrefix(message, m.Symbol)), m.Level
+		}
+	}
+	return message, "info"
+}
+
+// emitEvent writes an event in the plain or JSON format
+func emitEvent(event outputEvent) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	event.Time = time.Now().UTC()
+	if outputMode == outputJSON {
+		content, _ := json.Marshal(event)
+		fmt.Fprintln(os.Stdout, string(content))
+		return
+	}
+
+	text := event.Message
+	switch event.Event {
+	case "step":
+		text = fmt.Sprintf("step %s %s", event.Step, event.Status)
+		if event.Duration != "" {
+			text += " in " + event.Duration
+		}
+	case "command":
+		text = fmt.Sprintf("$ %s (exit %d in %s)", event.Command, *event.ExitCode, event.Duration)
+		if event.Step != "" {
+			text = fmt.Sprintf("[%s] %s", event.Step, text)
+		}
+	case "table":
+		lines := make([]string, len(event.Rows))
+		for i, row := range event.Rows {
+			cells := make([]string, len(row))
+			for j, cell := range row {
+				cells[j] = fmt.Sprintf("%s=%s", event.Headers[j], cell)
+			}
+			lines[i] = strings.Join(cells, " ")
+		}
+		text = strings.Join(lines, "\n")
+	}
+
+	level := strings.ToUpper(event.Level)
+	if level == "" {
+		level = "INFO"
+	}
+	timestamp := event.Time.Format(time.RFC3339)
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(os.Stdout, "%s %-7s %s\n", timestamp, level, line)
+	}
+	for _, output := range []string{event.Stdout, event.Stderr} {
+		for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+			if line != "" {
+				fmt.Fprintf(os.Stdout, "%s %-7s   | %s\n", timestamp, level, line)
+			}
+		}
+	}
+}
+
+// showIntro prints the title of a run
+func showIntro(title string) {
+	if outputMode == outputPretty {
+		tap.Intro(title)
+		return
+	}
+	emitEvent(outputEvent{Event: "intro", Message: title})
+}
+
+// showMessage prints a progress message, warnings are raised with warn instead
+func showMessage(message string) {
+	if outputMode == outputPretty {
+		tap.Message(message)
+		return
+	}
+	text, level := splitMessage(message)
+	emitEvent(outputEvent{Event: "message", Level: level, Message: text})
+}
+
+// warn prints a warning and records it for the report and --strict.
+// step is the install step that raised it, empty outside of a step.
+func warn(step, message string) {
+	warnings = append(warnings, reportWarning{Step: step, Message: strings.TrimSpace(message)})
+	showMessage("⚠️ " + message)
+}
+
+// showOutro prints the final result of a run
+func showOutro(message string) {
+	if outputMode == outputPretty {
+		tap.Outro(message)
+		return
+	}
+	text, level := splitMessage(message)
+	emitEvent(outputEvent{Event: "outro", Level: level, Message: text})
+}
+
+// showTable prints a table
+func showTable(headers []string, rows [][]string, opts tap.TableOptions) {
+	if outputMode == outputPretty {
+		tap.Table(headers, rows, opts)
+		return
+	}
+	emitEvent(outputEvent{Event: "table", Headers: headers, Rows: rows})
+}
+
+// showStep reports the result of an install step.
+// Pretty output prints the message, plain and JSON output a step event.
+func showStep(result stepResult, message string) {
+	if outputMode == outputPretty {
+		tap.Message(message)
+		return
+	}
+
+	event := outputEvent{Event: "step", Step: result.Name, Status: result.Status}
+	if result.Status != "skipped" {
+		event.Duration = result.Duration.Round(time.Millisecond).String()
+	}
+	if result.Status == "failed" {
+		event.Level = "error"
+	}
+	emitEvent(event)
+}
+
+// spinner is the progress indicator of a long running operation
+type spinner interface {
+	Start(message string)
+	Stop(message string, code int)
+}
+
+// newSpinner returns an animated spinner in pretty output, otherwise one that prints a line per update
+func newSpinner() spinner {
+	if outputMode == outputPretty {
+		return prettySpinner{tap.NewSpinner(tap.SpinnerOptions{Indicator: "dots"})}
+	}
+	return lineSpinner{}
+}
+
+// prettySpinner is the animated tap spinner
+type prettySpinner struct {
+	*tap.Spinner
+}
+
+func (s prettySpinner) Stop(message string, code int) {
+	s.Spinner.Stop(message, code)
+}
+
+// lineSpinner prints the spinner updates as events
+type lineSpinner struct{}
+
+func (lineSpinner) Start(message string) {
+	emitEvent(outputEvent{Event: "spinner", Level: "info", Message: strings.TrimSuffix(message, "...")})
+}
+
+func (lineSpinner) Stop(message string, code int) {
+	text, level := splitMessage(message)
+	if code != 0 && level == "info" {
+		level = "error"
+	}
+	emitEvent(outputEvent{Event: "spinner", Level: level, Message: text})
+}
+
+// runExternal runs an external command. Pretty output passes its output through,
+// plain and JSON output capture it and report it with the command event, together with the step running it.
+// A failure is returned as *commandError with the stderr of the command.
+func runExternal(ctx context.Context, cmd *exec.Cmd) error {
+	var stdout, stderr bytes.Buffer
+	if outputMode == outputPretty {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+		if err := cmd.Run(); err != nil {
+			return &commandError{Err: err, Stderr: stderr.String()}
+		}
+		return nil
+	}
+
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err := cmd.Run()
+	duration := time.Since(start)
+
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+		if cmd.ProcessState != nil {
+			exitCode = cmd.ProcessState.ExitCode()
+		}
+	}
+
+	event := outputEvent{
+		Event:    "command",
+		Step:     stepOf(ctx),
+		Duration: duration.Round(time.Millisecond).String(),
+		Command:  strings.Join(cmd.Args, " "),
+		Dir:      cmd.Dir,
+		ExitCode: &exitCode,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	}
+	if err != nil {
+		event.Level = "error"
+	}
+	emitEvent(event)
+	if err != nil {
+		return &commandError{Err: err, Stderr: stderr.String()}
+	}
+	return nil
+}
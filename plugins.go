@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/yarlson/tap"
+)
+
+const pluginPrefix = "webcore-go-install-"
+
+// plugin represents an installer extension found on PATH
+type plugin struct {
+	Name string
+	Path string
+}
+
+// findPlugins lists all webcore-go-install-<name> executables on PATH.
+// When the same plugin exists in several directories, the first one wins like it does for the shell.
+func findPlugins() []plugin {
+	seen := make(map[string]bool)
+	plugins := make([]plugin, 0)
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name := pluginName(entry.Name())
+			if name == "" || seen[name] || entry.IsDir() {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			seen[name] = true
+			plugins = append(plugins, plugin{Name: name, Path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins
+}
+
+// pluginName returns the plugin name of an executable file name, or an empty string if it isn't a plugin
+func pluginName(fileName string) string {
+	if !strings.HasPrefix(fileName, pluginPrefix) {
+		return ""
+	}
+
+	name := strings.TrimPrefix(fileName, pluginPrefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name
+}
+
+// isExecutable checks if the file at path can be executed
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0111 != 0
+}
+
+// runPluginsCommand handles the "plugins" subcommand
+func runPluginsCommand(args []string) error {
+	if len(args) == 0 || args[0] != "list" {
+		return invalidInputError("", fmt.Errorf("usage: webcore-go-install plugins list"))
+	}
+
+	plugins := findPlugins()
+	if len(plugins) == 0 {
+		showMessage(fmt.Sprintf("No plugins found on PATH (executables named %s<name>)", pluginPrefix))
+		return nil
+	}
+
+	rows := make([][]string, 0, len(plugins))
+	for _, p := range plugins {
+		rows = append(rows, []string{p.Name, p.Path})
+	}
+	showTable([]string{"Plugin", "Path"}, rows, tap.TableOptions{ShowBorders: true})
+	return nil
+}
+
+// runPlugin executes the webcore-go-install-<name> plugin and returns its exit code.
+// The plugin gets the project root and the lock file path as WEBCORE_PROJECT_ROOT and WEBCORE_LOCK_FILE,
+// together with the installed configuration from the lock file.
+func runPlugin(ctx context.Context, name string, args []string) (int, error) {
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return exitCodeInvalidInput, invalidInputError("run webcore-go-install plugins list to see the installed plugins", fmt.Errorf("unknown command %q: no %s%s found on PATH", name, pluginPrefix, name))
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return 1, err
+	}
+
+	env := os.Environ()
+	projectRoot, lockPath := findProjectRoot(cwd)
+	if lockPath != "" {
+		lock, err := readLockFile(lockPath)
+		if err != nil {
+			return exitCodeOf(err), err
+		}
+		env = append(env, configEnv(lock.Config)...)
+		env = append(env, "WEBCORE_PROJECT_DIR="+projectRoot)
+	}
+	env = append(env, "WEBCORE_PROJECT_ROOT="+projectRoot, "WEBCORE_LOCK_FILE="+lockPath)
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return 1, fmt.Errorf("failed to run plugin %s: %w", path, err)
+	}
+	return 0, nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yarlson/tap"
+)
+
+// presetCustom is the preset choice that answers every question without a preset
+const presetCustom = "custom"
+
+// Preset bundles the libraries, project mode and features of a project archetype under a name
+type Preset struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Libraries   []string `yaml:"libraries"`
+	Mode        string   `yaml:"mode"`
+	Features    []string `yaml:"features"`
+
+	source string
+}
+
+// Built-in presets, the user and organization config files can add more
+var availablePresets = []Preset{
+	{
+		Name:        "rest-api",
+		Description: "REST API on Postgres with API key auth",
+		Libraries:   []string{"database:postgres", "authstorage:yaml", "authentication:apikey"},
+		Mode:        "simple",
+		Features:    []string{"specific config", "database repository", "http request handler"},
+	},
+	{
+		Name:        "kafka-worker",
+		Description: "Kafka consumer worker",
+		Libraries:   []string{"kafka:consumer"},
+		Mode:        "simple",
+		Features:    []string{"specific config"},
+	},
+	{
+		Name:        "pubsub-processor",
+		Description: "Pub/Sub event processor",
+		Libraries:   []string{"pubsub"},
+		Mode:        "simple",
+		Features:    []string{"specific config"},
+	},
+}
+
+// loadPresets returns the built-in presets and those of the organization and user config files.
+// A preset replaces an earlier one with the same name, so the user config file wins.
+func loadPresets() ([]Preset, error) {
+	presets := make([]Preset, 0, len(availablePresets))
+	for _, preset := range availablePresets {
+		preset.source = "built-in presets"
+		presets = append(presets, preset)
+	}
+
+	orgFile, err := loadOrgConfig()
+	if err != nil {
+		return nil, invalidInputError(fmt.Sprintf("fix the file or unset %s", orgConfigEnv), err)
+	}
+	userFile, err := loadUserConfig()
+	if err != nil {
+		return nil, invalidInputError("fix the user config file", err)
+	}
+
+	for _, file := range []*userConfig{orgFile, userFile} {
+		for _, preset := range file.Presets {
+			if preset.Name == "" || preset.Name == presetCustom {
+				return nil, invalidInputError(fmt.Sprintf("fix the presets in %s", file.path), fmt.Errorf("invalid preset name %q in %s", preset.Name, file.path))
+			}
+			preset.source = file.path
+			presets = replacePreset(presets, preset)
+		}
+	}
+	return presets, nil
+}
+
+// replacePreset replaces the preset with the same name or appends it
+func replacePreset(presets []Preset, preset Preset) []Preset {
+	for i := range presets {
+		if presets[i].Name == preset.Name {
+			presets[i] = preset
+			return presets
+		}
+	}
+	return append(presets, preset)
+}
+
+// findPreset looks up a preset by name
+func findPreset(presets []Preset, name string) (*Preset, error) {
+	names := make([]string, len(presets))
+	for i := range presets {
+		if presets[i].Name == name {
+			return &presets[i], nil
+		}
+		names[i] = presets[i].Name
+	}
+	return nil, invalidInputError(fmt.Sprintf("available presets: %s", strings.Join(names, ", ")), fmt.Errorf("unknown preset %q", name))
+}
+
+// selectPreset asks for a preset or a custom configuration, nil means custom
+func selectPreset(ctx context.Context, presets []Preset) *Preset {
+	options := []tap.SelectOption[string]{{Label: "Custom (answer every question)", Value: presetCustom}}
+	for _, preset := range presets {
+		options = append(options, tap.SelectOption[string]{
+			Label: fmt.Sprintf("%s - %s", preset.Name, preset.Description),
+			Value: preset.Name,
+		})
+	}
+
+	name := promptSelect(ctx, tap.SelectOptions[string]{
+		Message:      "Start from a preset?",
+		Options:      options,
+		InitialValue: &options[0].Value,
+	})
+	if name == presetCustom {
+		return nil
+	}
+
+	preset, _ := findPreset(presets, name)
+	showMessage(fmt.Sprintf("✅ Preset: %s\n", preset.Name))
+	return preset
+}
+
+// applyPreset prefills the libraries, project mode and features of preset.
+// Answers set by an environment variable or the answers file keep their value.
+func applyPreset(config *Config, preset *Preset) error {
+	values := map[string]string{
+		"libraries": strings.Join(preset.Libraries, ","),
+		"mode":      preset.Mode,
+		"features":  strings.Join(preset.Features, ","),
+	}
+
+	for _, field := range []string{"libraries", "mode", "features"} {
+		if field == "mode" && preset.Mode == "" {
+			continue
+		}
+		if isEnvSource(answerSources[field]) || answerSources[field] == sourceAnswersFile {
+			continue
+		}
+		if err := setAnswer(config, field, values[field]); err != nil {
+			return invalidInputError(fmt.Sprintf("fix the preset %s in %s", preset.Name, preset.source), fmt.Errorf("invalid %s in preset %s: %w", field, preset.Name, err))
+		}
+		answerSources[field] = "preset " + preset.Name
+	}
+	return nil
+}
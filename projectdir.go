@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yarlson/tap"
+)
+
+// Project directory states detected before downloading the template
+const (
+	dirEmpty     = "empty"     // missing or empty directory
+	dirForeign   = "foreign"   // directory with content that isn't a webcore project
+	dirTemplate  = "template"  // pristine template, nothing installed yet
+	dirInstalled = "installed" // completed installation (lock file present)
+	dirPartial   = "partial"   // installation that stopped halfway
+)
+
+// Actions taken for the project directory
+const (
+	dirActionClone       = "clone"       // download the template into the directory
+	dirActionUse         = "use"         // use the template already in the directory
+	dirActionResume      = "resume"      // continue a partial installation
+	dirActionReconfigure = "reconfigure" // regenerate libraries and config files of an installed project
+	dirActionSubdir      = "subdir"      // install into a subdirectory instead
+	dirActionAbort       = "abort"
+)
+
+// reconfigureSteps are the steps that can be applied again to an installed project
+var reconfigureSteps = []string{"libraries", "go-get", "config-files", "compose"}
+
+// detectProjectDirState inspects the project directory and returns its state
+func detectProjectDirState(projectDir string) (string, error) {
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return dirEmpty, nil
+		}
+		return "", err
+	}
+	if len(entries) == 0 {
+		return dirEmpty, nil
+	}
+
+	if fileExists(filepath.Join(projectDir, lockFileName)) {
+		return dirInstalled, nil
+	}
+
+	if fileExists(filepath.Join(projectDir, checkpointFileName)) {
+		return dirPartial, nil
+	}
+
+	if !fileExists(filepath.Join(projectDir, "webcore", "go.mod")) {
+		return dirForeign, nil
+	}
+
+	if isPristineTemplate(projectDir) {
+		return dirTemplate, nil
+	}
+	return dirPartial, nil
+}
+
+// isPristineTemplate checks that none of the install steps has touched the template yet
+func isPristineTemplate(projectDir string) bool {
+	if !fileExists(filepath.Join(projectDir, "modules", "dummy")) {
+		return false
+	}
+	if fileExists(filepath.Join(projectDir, "config.yaml")) || fileExists(filepath.Join(projectDir, "access.yaml")) {
+		return false
+	}
+
+	return fileContains(filepath.Join(projectDir, "webcore", "main.go"), "github.com/semanggilab/webcorego-template-app")
+}
+
+// fileExists checks if a file or directory exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// fileContains checks if the file exists and contains s
+func fileContains(path, s string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(content), s)
+}
+
+// prepareProjectDir detects the state of the project directory and asks what to do with it.
+// It may change config.ProjectDir when the user chooses to install into a subdirectory.
+func prepareProjectDir(ctx context.Context, config *Config) (string, error) {
+	for {
+		state, err := detectProjectDirState(config.ProjectDir)
+		if err != nil {
+			return "", fmt.Errorf("failed to inspect %s: %w", config.ProjectDir, err)
+		}
+
+		var message string
+		var options []tap.SelectOption[string]
+		switch state {
+		case dirEmpty:
+			return dirActionClone, nil
+		case dirTemplate:
+			showMessage(fmt.Sprintf("⏭️ %s already contains the template, skipping download", config.ProjectDir))
+			return dirActionUse, nil
+		case dirForeign:
+			message = fmt.Sprintf("%s is not empty and doesn't contain a WebCore project", config.ProjectDir)
+			options = []tap.SelectOption[string]{
+				{Label: "Abort", Value: dirActionAbort},
+				{Label: "Install into a subdirectory", Value: dirActionSubdir},
+			}
+		case dirInstalled:
+			message = fmt.Sprintf("%s already contains an installed WebCore project", config.ProjectDir)
+			options = []tap.SelectOption[string]{
+				{Label: "Abort", Value: dirActionAbort},
+				{Label: "Reconfigure libraries and config files", Value: dirActionReconfigure, Hint: strings.Join(reconfigureSteps, ", ")},
+				{Label: "Install into a subdirectory", Value: dirActionSubdir},
+			}
+		case dirPartial:
+			message = fmt.Sprintf("%s contains a partially installed WebCore project", config.ProjectDir)
+			options = []tap.SelectOption[string]{
+				{Label: "Resume the installation", Value: dirActionResume},
+				{Label: "Abort", Value: dirActionAbort},
+			}
+		}
+
+		action := promptSelect(ctx, tap.SelectOptions[string]{
+			Message:      message + ". What do you want to do?",
+			Options:      options,
+			InitialValue: &options[0].Value,
+		})
+
+		switch action {
+		case dirActionSubdir:
+			config.ProjectDir = filepath.Join(config.ProjectDir, askSubdirName(ctx))
+			showMessage(fmt.Sprintf("✅ Project directory: %s\n", config.ProjectDir))
+			continue
+		case dirActionAbort:
+			return dirActionAbort, invalidInputError("choose an empty directory with --dir", fmt.Errorf("%s, installation aborted", message))
+		}
+
+		showMessage(fmt.Sprintf("✅ Project directory action: %s\n", action))
+		return action, nil
+	}
+}
+
+// askSubdirName asks for the name of the subdirectory to install into
+func askSubdirName(ctx context.Context) string {
+	for {
+		name := promptText(ctx, tap.TextOptions{
+			Message:      "Enter subdirectory name",
+			Placeholder:  "webcore",
+			InitialValue: "webcore",
+		})
+
+		name = strings.Trim(name, "/\\")
+		if name == "" || strings.Contains(name, "..") {
+			showMessage("❌ Invalid subdirectory name")
+			continue
+		}
+		return name
+	}
+}
+
+// confirmOverwrite asks before an existing file is overwritten.
+// Missing files can always be written, in non-interactive mode existing files are kept.
+func confirmOverwrite(ctx context.Context, path string) bool {
+	if !fileExists(path) {
+		return true
+	}
+
+	overwrite := promptConfirm(ctx, tap.ConfirmOptions{
+		Message:      fmt.Sprintf("%s already exists. Overwrite it?", path),
+		InitialValue: false,
+	})
+	if !overwrite {
+		showMessage(fmt.Sprintf("⏭️ Keeping existing %s", path))
+	}
+	return overwrite
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+
+	"github.com/yarlson/tap"
+)
+
+// nonInteractive makes every prompt take its initial value instead of asking
+var nonInteractive bool
+
+// Cancelled prompts (Esc, or Ctrl-C which cancels the context) stop the installer through exitCancelled.
+// The cancel keys are noted by the prompt input, so an empty answer is a valid answer.
+
+// runPrompt runs a prompt on the terminal, which is restored before the answer is returned
+func runPrompt[T any](ctx context.Context, prompt func(in tap.Reader, out tap.Writer) T) T {
+	terminal, err := openPromptTerminal()
+	if err != nil {
+		exitWithError("Failed to start the prompts", invalidInputError("run with --non-interactive", err))
+	}
+	in := newPromptInput(terminal)
+	value := prompt(in, resizeOutput{terminal})
+	if err := terminal.Close(); err != nil {
+		exitWithError("Failed to restore the terminal", err)
+	}
+
+	if in.cancelled.Load() || ctx.Err() != nil {
+		exitCancelled()
+	}
+	return value
+}
+
+// promptText asks for a text value, or returns the initial value in non-interactive mode
+func promptText(ctx context.Context, opts tap.TextOptions) string {
+	if nonInteractive {
+		return opts.InitialValue
+	}
+
+	return runPrompt(ctx, func(in tap.Reader, out tap.Writer) string {
+		opts.Input, opts.Output = in, out
+		return tap.Text(ctx, opts)
+	})
+}
+
+// promptSelect asks to select a single option, or returns the initial value in non-interactive mode
+func promptSelect(ctx context.Context, opts tap.SelectOptions[string]) string {
+	if nonInteractive {
+		if opts.InitialValue != nil {
+			return *opts.InitialValue
+		}
+		if len(opts.Options) > 0 {
+			return opts.Options[0].Value
+		}
+		return ""
+	}
+
+	return runPrompt(ctx, func(in tap.Reader, out tap.Writer) string {
+		opts.Input, opts.Output = in, out
+		return tap.Select(ctx, opts)
+	})
+}
+
+// promptMultiSelect asks to select several options, or returns the initial values in non-interactive mode
+func promptMultiSelect(ctx context.Context, opts tap.MultiSelectOptions[string]) []string {
+	if nonInteractive {
+		return opts.InitialValues
+	}
+
+	return runPrompt(ctx, func(in tap.Reader, out tap.Writer) []string {
+		opts.Input, opts.Output = in, out
+		return tap.MultiSelect(ctx, opts)
+	})
+}
+
+// promptConfirm asks a yes/no question, or returns the initial value in non-interactive mode
+func promptConfirm(ctx context.Context, opts tap.ConfirmOptions) bool {
+	if nonInteractive {
+		return opts.InitialValue
+	}
+
+	return runPrompt(ctx, func(in tap.Reader, out tap.Writer) bool {
+		opts.Input, opts.Output = in, out
+		return tap.Confirm(ctx, opts)
+	})
+}
+
+// promptPassword asks for a secret without echoing it, or returns the initial value in non-interactive mode
+func promptPassword(ctx context.Context, opts tap.PasswordOptions) string {
+	if nonInteractive {
+		return opts.InitialValue
+	}
+
+	return runPrompt(ctx, func(in tap.Reader, out tap.Writer) string {
+		opts.Input, opts.Output = in, out
+		return tap.Password(ctx, opts)
+	})
+}
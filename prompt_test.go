@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yarlson/tap"
+)
+
+// listeningReader is mock input that reports when the prompt started listening for keys
+type listeningReader struct {
+	*tap.MockReadable
+	listening chan struct{}
+}
+
+func (r listeningReader) On(event string, handler func(string, tap.Key)) {
+	r.MockReadable.On(event, handler)
+	close(r.listening)
+}
+
+// runTextPrompt runs a text prompt on mock input, sends keys once it is listening and returns the answer
+func runTextPrompt(t *testing.T, keys ...tap.Key) (string, *promptInput) {
+	t.Helper()
+	mock := listeningReader{MockReadable: tap.NewMockReadable(), listening: make(chan struct{})}
+	in := newPromptInput(mock)
+
+	result := make(chan string, 1)
+	go func() {
+		result <- tap.Text(context.Background(), tap.TextOptions{Message: "Answer", Input: in, Output: tap.NewMockWritable()})
+	}()
+	<-mock.listening
+	for _, key := range keys {
+		char := ""
+		if key.Rune != 0 {
+			char = string(key.Rune)
+		}
+		mock.SendKey(char, key)
+	}
+
+	select {
+	case value := <-result:
+		return value, in
+	case <-time.After(5 * time.Second):
+		t.Fatal("prompt didn't finish")
+		return "", nil
+	}
+}
+
+func TestPromptEmptyAnswerIsNotCancelled(t *testing.T) {
+	value, in := runTextPrompt(t, tap.Key{Name: "return"})
+	if value != "" {
+		t.Errorf("value = %q, want an empty answer", value)
+	}
+	if in.cancelled.Load() {
+		t.Error("an empty answer was taken as a cancelled prompt")
+	}
+}
+
+func TestPromptAnswer(t *testing.T) {
+	value, in := runTextPrompt(t, tap.Key{Name: "o", Rune: 'o'}, tap.Key{Name: "k", Rune: 'k'}, tap.Key{Name: "return"})
+	if value != "ok" || in.cancelled.Load() {
+		t.Errorf("value = %q, cancelled = %v, want \"ok\" and not cancelled", value, in.cancelled.Load())
+	}
+}
+
+// The prompt library must cancel on the keys isCancelKey notes, or a cancelled prompt would be taken as an answer
+func TestPromptCancelKeys(t *testing.T) {
+	for _, key := range []tap.Key{{Name: "escape"}, {Name: "c", Rune: 'c', Ctrl: true}} {
+		value, in := runTextPrompt(t, tap.Key{Name: "x", Rune: 'x'}, key)
+		if !in.cancelled.Load() {
+			t.Errorf("%+v didn't cancel the prompt", key)
+		}
+		if value != "" {
+			t.Errorf("%+v: value = %q, want no answer from a cancelled prompt", key, value)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// runRenameModuleCommand handles the "rename-module" subcommand
+func runRenameModuleCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("rename-module", flag.ContinueOnError)
+	from := flags.String("from", "", "current module path (prefix) to rename")
+	to := flags.String("to", "", "new module path")
+	dir := flags.String("dir", "", "project directory (default: the project containing the current directory)")
+	skipVerify := flags.Bool("skip-verify", false, "don't run go build after renaming")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *from == "" || *to == "" {
+		return invalidInputError("", fmt.Errorf("usage: webcore-go-install rename-module --from <old module path> --to <new module path>"))
+	}
+	if *from == *to {
+		return invalidInputError("", fmt.Errorf("--from and --to are the same module path"))
+	}
+	if err := validateModuleName(*to); err != nil {
+		return invalidInputError("use a module path like github.com/yourorg/project", fmt.Errorf("invalid module name %q: %w", *to, err))
+	}
+
+	projectDir, err := resolveProjectDir(*dir)
+	if err != nil {
+		return err
+	}
+
+	// --from may be a legacy path the installer wouldn't create, it only has to be the current module path
+	webcoreGoMod, err := readGoMod(filepath.Join(projectDir, "webcore", "go.mod"))
+	if err != nil {
+		return invalidInputError("run the command in a WebCore project or set --dir", err)
+	}
+	if current := webcoreGoMod.Module.Mod.Path; *from != current {
+		return invalidInputError(fmt.Sprintf("use the module path of webcore/go.mod: --from %s", current), fmt.Errorf("--from %q isn't the current module path %q", *from, current))
+	}
+
+	showIntro(fmt.Sprintf("Renaming module %s to %s", *from, *to))
+
+	if err := renameModule(projectDir, *from, *to); err != nil {
+		return err
+	}
+
+	if !*skipVerify {
+		if err := verifyBuild(ctx, projectDir); err != nil {
+			return err
+		}
+	}
+
+	showOutro(fmt.Sprintf("✅ Module renamed to %s", *to))
+	return nil
+}
+
+// resolveProjectDir returns dir if set, otherwise the project root containing the current directory
+func resolveProjectDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	if projectRoot, _ := findProjectRoot(cwd); projectRoot != "" {
+		return projectRoot, nil
+	}
+	return cwd, nil
+}
+
+// renamedFile is the new content of a file changed by the rename
+type renamedFile struct {
+	Path    string
+	Content []byte
+	Message string
+}
+
+// renameModule rewrites every reference to the from module path inside the project.
+// All files are parsed and renamed in memory first, so a file that fails to parse leaves the project unchanged.
+func renameModule(projectDir, from, to string) error {
+	files := make([]renamedFile, 0)
+	add := func(file *renamedFile, err error) error {
+		if err != nil {
+			var ie *installError
+			if errors.As(err, &ie) {
+				return err
+			}
+			return newInstallError(exitCodeRewrite, "fix the file, nothing was renamed", err)
+		}
+		if file != nil {
+			files = append(files, *file)
+		}
+		return nil
+	}
+
+	for _, sub := range []string{"webcore", "modules"} {
+		root := filepath.Join(projectDir, sub)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				if info.Name() == "vendor" || (strings.HasPrefix(info.Name(), ".") && path != root) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			switch {
+			case info.Name() == "go.mod":
+				return add(renameInGoMod(path, from, to))
+			case strings.HasSuffix(path, ".go"):
+				return add(renameImports(path, from, to))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := add(renameInGoWork(filepath.Join(projectDir, "go.work"), from, to)); err != nil {
+		return err
+	}
+	if err := add(renameInLockFile(filepath.Join(projectDir, lockFileName), from, to)); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := os.WriteFile(file.Path, file.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+		showMessage(file.Message)
+	}
+	return nil
+}
+
+// renamePath returns the renamed path if path is the from module or a package inside it
+func renamePath(path, from, to string) (string, bool) {
+	if path == from {
+		return to, true
+	}
+	if strings.HasPrefix(path, from+"/") {
+		return to + strings.TrimPrefix(path, from), true
+	}
+	return path, false
+}
+
+// renameInGoMod renames the module directive, requirements and replacements of a go.mod file, nil when nothing changes
+func renameInGoMod(goModPath, from, to string) (*renamedFile, error) {
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := modfile.Parse(goModPath, content, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	if f.Module != nil {
+		if newPath, ok := renamePath(f.Module.Mod.Path, from, to); ok {
+			if err := f.AddModuleStmt(newPath); err != nil {
+				return nil, err
+			}
+			changed = true
+		}
+	}
+
+	for _, req := range append([]*modfile.Require(nil), f.Require...) {
+		if newPath, ok := renamePath(req.Mod.Path, from, to); ok {
+			version, indirect := req.Mod.Version, req.Indirect
+			if err := f.DropRequire(req.Mod.Path); err != nil {
+				return nil, err
+			}
+			f.AddNewRequire(newPath, version, indirect)
+			changed = true
+		}
+	}
+
+	replaced, err := renameReplaces(f.Replace, from, to, f.DropReplace, f.AddReplace)
+	if err != nil {
+		return nil, err
+	}
+
+	if !changed && !replaced {
+		return nil, nil
+	}
+
+	f.Cleanup()
+	return &renamedFile{Path: goModPath, Content: modfile.Format(f.Syntax), Message: fmt.Sprintf("📝 Updated %s", goModPath)}, nil
+}
+
+// renameReplaces rewrites the module paths used in replace directives
+func renameReplaces(replaces []*modfile.Replace, from, to string, drop func(string, string) error, add func(string, string, string, string) error) (bool, error) {
+	changed := false
+	for _, rep := range append([]*modfile.Replace(nil), replaces...) {
+		oldPath, oldRenamed := renamePath(rep.Old.Path, from, to)
+		newPath, newRenamed := rep.New.Path, false
+		if !modfile.IsDirectoryPath(rep.New.Path) {
+			newPath, newRenamed = renamePath(rep.New.Path, from, to)
+		}
+		if !oldRenamed && !newRenamed {
+			continue
+		}
+
+		// drop clears the directive, so its versions are read first
+		oldVersion, newVersion := rep.Old.Version, rep.New.Version
+		if err := drop(rep.Old.Path, oldVersion); err != nil {
+			return changed, err
+		}
+		if err := add(oldPath, oldVersion, newPath, newVersion); err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// renameImports renames the import paths of a Go file, nil when nothing changes.
+// Only the import path literals found by the parser are touched, the rest of the file is kept as is.
+func renameImports(goFilePath, from, to string) (*renamedFile, error) {
+	content, err := os.ReadFile(goFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, goFilePath, content, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	type edit struct {
+		start, end int
+		value      string
+	}
+	edits := make([]edit, 0)
+
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		newPath, ok := renamePath(importPath, from, to)
+		if !ok {
+			continue
+		}
+
+		start := fset.Position(spec.Path.Pos()).Offset
+		end := fset.Position(spec.Path.End()).Offset
+		edits = append(edits, edit{start: start, end: end, value: strconv.Quote(newPath)})
+	}
+
+	if len(edits) == 0 {
+		return nil, nil
+	}
+
+	// Apply edits from the end of the file so earlier offsets stay valid
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		content = append(content[:e.start], append([]byte(e.value), content[e.end:]...)...)
+	}
+
+	return &renamedFile{Path: goFilePath, Content: content, Message: fmt.Sprintf("📝 Updated imports in %s", goFilePath)}, nil
+}
+
+// renameInGoWork renames the module paths used in go.work replace directives, nil when nothing changes
+func renameInGoWork(goWorkPath, from, to string) (*renamedFile, error) {
+	content, err := os.ReadFile(goWorkPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	f, err := modfile.ParseWork(goWorkPath, content, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := renameReplaces(f.Replace, from, to, f.DropReplace, f.AddReplace)
+	if err != nil || !changed {
+		return nil, err
+	}
+
+	f.Cleanup()
+	return &renamedFile{Path: goWorkPath, Content: modfile.Format(f.Syntax), Message: fmt.Sprintf("📝 Updated %s", goWorkPath)}, nil
+}
+
+// renameInLockFile renames the module names recorded in the lock file, nil when there is no lock file
+func renameInLockFile(lockPath, from, to string) (*renamedFile, error) {
+	lock, err := readLockFile(lockPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var moduleRenamed, modRenamed bool
+	lock.Config.ModuleName, moduleRenamed = renamePath(lock.Config.ModuleName, from, to)
+	lock.Config.ModuleModName, modRenamed = renamePath(lock.Config.ModuleModName, from, to)
+	if !moduleRenamed && !modRenamed {
+		return nil, nil
+	}
+
+	content, err := lockFileContent(lock)
+	if err != nil {
+		return nil, err
+	}
+	return &renamedFile{Path: lockPath, Content: content, Message: fmt.Sprintf("📝 Updated %s", lockPath)}, nil
+}
+
+// verifyBuild runs go build in webcore and in every module of the project
+func verifyBuild(ctx context.Context, projectDir string) error {
+	sp := newSpinner()
+	sp.Start("Verifying build...")
+
+	dirs := []string{filepath.Join(projectDir, "webcore")}
+	moduleDirs, _ := filepath.Glob(filepath.Join(projectDir, "modules", "*", "go.mod"))
+	for _, goMod := range moduleDirs {
+		dirs = append(dirs, filepath.Dir(goMod))
+	}
+
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+			continue
+		}
+
+		cmd := exec.CommandContext(ctx, "go", "build", "./...")
+		cmd.Dir = dir
+
+		if err := runExternal(ctx, cmd); err != nil {
+			sp.Stop(fmt.Sprintf("❌ Build failed in %s", dir), 1)
+			return newInstallError(exitCodeVerification, "fix the build errors above, or skip the check with --skip-verify", fmt.Errorf("go build failed in %s: %w", dir, err))
+		}
+	}
+
+	sp.Stop("✅ Build verified", 0)
+	return nil
+}
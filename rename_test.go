@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	renameFrom = "github.com/acme/orders"
+	renameTo   = "github.com/acme/billing"
+)
+
+func TestRenamePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		renamed bool
+	}{
+		{"github.com/acme/orders", "github.com/acme/billing", true},
+		{"github.com/acme/orders/modules/api", "github.com/acme/billing/modules/api", true},
+		{"github.com/acme/orders-v2", "github.com/acme/orders-v2", false},
+		{"github.com/acme/ordersapi/x", "github.com/acme/ordersapi/x", false},
+		{"github.com/acme", "github.com/acme", false},
+		{"example.com/github.com/acme/orders", "example.com/github.com/acme/orders", false},
+	}
+	for _, tt := range tests {
+		got, renamed := renamePath(tt.path, renameFrom, renameTo)
+		if got != tt.want || renamed != tt.renamed {
+			t.Errorf("renamePath(%q) = %q, %v, want %q, %v", tt.path, got, renamed, tt.want, tt.renamed)
+		}
+	}
+}
+
+func TestRenameInGoMod(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string // renamed go.mod, none when nothing changes
+	}{
+		{
+			name:    "module",
+			content: "module github.com/acme/orders\n\ngo 1.25.0\n",
+			want:    "module github.com/acme/billing\n\ngo 1.25.0\n",
+		},
+		{
+			name: "require and replace of a module",
+			content: "module github.com/acme/orders/modules/api\n\ngo 1.25.0\n\n" +
+				"require (\n\tgithub.com/acme/orders v0.0.0-00010101000000-000000000000\n\tgolang.org/x/mod v0.29.0 // indirect\n)\n\n" +
+				"replace github.com/acme/orders => ../../webcore\n",
+			want: "module github.com/acme/billing/modules/api\n\ngo 1.25.0\n\n" +
+				"require (\n\tgolang.org/x/mod v0.29.0 // indirect\n\tgithub.com/acme/billing v0.0.0-00010101000000-000000000000\n)\n\n" +
+				"replace github.com/acme/billing => ../../webcore\n",
+		},
+		{
+			name:    "replacement module path",
+			content: "module example.com/app\n\ngo 1.25.0\n\nreplace example.com/lib => github.com/acme/orders/lib v1.2.0\n",
+			want:    "module example.com/app\n\ngo 1.25.0\n\nreplace example.com/lib => github.com/acme/billing/lib v1.2.0\n",
+		},
+		{
+			name:    "replaced version",
+			content: "module example.com/app\n\ngo 1.25.0\n\nreplace github.com/acme/orders v1.0.0 => ./orders\n",
+			want:    "module example.com/app\n\ngo 1.25.0\n\nreplace github.com/acme/billing v1.0.0 => ./orders\n",
+		},
+		{
+			name:    "unrelated",
+			content: "module github.com/acme/orders-v2\n\ngo 1.25.0\n\nrequire golang.org/x/mod v0.29.0\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, t.TempDir(), "go.mod", tt.content)
+			renamed, err := renameInGoMod(path, renameFrom, renameTo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" {
+				if renamed != nil {
+					t.Errorf("go.mod renamed to %q, want it unchanged", renamed.Content)
+				}
+				return
+			}
+			if renamed == nil {
+				t.Fatal("go.mod not renamed")
+			}
+			if string(renamed.Content) != tt.want {
+				t.Errorf("go.mod = %q, want %q", renamed.Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameImports(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string // renamed file, none when nothing changes
+	}{
+		{
+			name: "imports",
+			content: "package api\n\nimport (\n\t\"fmt\"\n\n\torders \"github.com/acme/orders/deps\"\n\t\"github.com/acme/orders\"\n)\n\n" +
+				"// see github.com/acme/orders/deps\nvar s = \"github.com/acme/orders\"\n",
+			want: "package api\n\nimport (\n\t\"fmt\"\n\n\torders \"github.com/acme/billing/deps\"\n\t\"github.com/acme/billing\"\n)\n\n" +
+				"// see github.com/acme/orders/deps\nvar s = \"github.com/acme/orders\"\n",
+		},
+		{
+			name:    "raw string import",
+			content: "package api\n\nimport `github.com/acme/orders/deps`\n",
+			want:    "package api\n\nimport \"github.com/acme/billing/deps\"\n",
+		},
+		{
+			name:    "unrelated",
+			content: "package api\n\nimport \"github.com/acme/orders-v2/deps\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, t.TempDir(), "api.go", tt.content)
+			renamed, err := renameImports(path, renameFrom, renameTo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" {
+				if renamed != nil {
+					t.Errorf("file renamed to %q, want it unchanged", renamed.Content)
+				}
+				return
+			}
+			if renamed == nil {
+				t.Fatal("imports not renamed")
+			}
+			if string(renamed.Content) != tt.want {
+				t.Errorf("file = %q, want %q", renamed.Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameInLockFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        Config
+		moduleName    string // renamed names, none when the lock file isn't written
+		moduleModName string
+	}{
+		{
+			name:          "mono-repo",
+			config:        Config{ModuleName: "github.com/acme/orders", ModuleModName: "github.com/acme/orders/modules/api"},
+			moduleName:    "github.com/acme/billing",
+			moduleModName: "github.com/acme/billing/modules/api",
+		},
+		{
+			name:       "simple",
+			config:     Config{ModuleName: "github.com/acme/orders"},
+			moduleName: "github.com/acme/billing",
+		},
+		{
+			name:   "unchanged",
+			config: Config{ModuleName: "github.com/acme/orders-v2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			content, err := lockFileContent(&installLock{Version: 1, Config: &config})
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := writeTestFile(t, t.TempDir(), lockFileName, string(content))
+
+			renamed, err := renameInLockFile(path, renameFrom, renameTo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.moduleName == "" {
+				if renamed != nil {
+					t.Errorf("lock file written as %q, want it unchanged", renamed.Content)
+				}
+				return
+			}
+			if renamed == nil {
+				t.Fatal("lock file not renamed")
+			}
+			lock := &installLock{}
+			if err := json.Unmarshal(renamed.Content, lock); err != nil {
+				t.Fatal(err)
+			}
+			if lock.Config.ModuleName != tt.moduleName || lock.Config.ModuleModName != tt.moduleModName {
+				t.Errorf("module names = %q, %q, want %q, %q", lock.Config.ModuleName, lock.Config.ModuleModName, tt.moduleName, tt.moduleModName)
+			}
+		})
+	}
+
+	renamed, err := renameInLockFile(filepath.Join(t.TempDir(), lockFileName), renameFrom, renameTo)
+	if renamed != nil || err != nil {
+		t.Errorf("missing lock file = %v, %v, want nothing", renamed, err)
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+)
+
+const reportVersion = 1
+
+// installReport is the machine-readable result of an installation, written with --report
+type installReport struct {
+	Version    int             `json:"version"`
+	Result     string          `json:"result"` // "success", "failed" or "cancelled"
+	Error      string          `json:"error,omitempty"`
+	Hint       string          `json:"hint,omitempty"`
+	ExitCode   int             `json:"exit_code"`
+	Template   string          `json:"template"`
+	Commit     string          `json:"template_commit,omitempty"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+	Duration   string          `json:"duration"`
+	Config     *Config         `json:"config"`
+	Steps      []reportStep    `json:"steps"`
+	Files      []fileChange    `json:"files"`
+	Libraries  []reportLibrary `json:"libraries"`
+	Warnings   []reportWarning `json:"warnings"`
+
+	jsonPath     string
+	markdownPath string
+	before       fileSnapshot
+}
+
+// reportStep is the result of a single install step
+type reportStep struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// reportLibrary is a selected library together with the module version go get resolved
+type reportLibrary struct {
+	Name        string `json:"name"`
+	PackagePath string `json:"package_path"`
+	Module      string `json:"module,omitempty"`
+	Version     string `json:"version,omitempty"` // empty when the library isn't in webcore/go.mod
+}
+
+// fileChange is a file created, modified, renamed or deleted by the installation
+type fileChange struct {
+	Path     string `json:"path"`
+	Change   string `json:"change"` // "created", "modified", "renamed" or "deleted"
+	From     string `json:"from,omitempty"`
+	Modified bool   `json:"modified,omitempty"` // renamed file whose content changed as well
+}
+
+// reportWarning is a warning of the run with the step that raised it, empty outside of a step
+type reportWarning struct {
+	Step    string `json:"step,omitempty"`
+	Message string `json:"message"`
+}
+
+// fileSnapshot maps the paths of the files in the project directory to the hash of their content
+type fileSnapshot map[string]string
+
+// warnings collects every warning raised with warn during the run, for the report and --strict
+var warnings []reportWarning
+
+// dependencyErr is the last failed go get, it decides the exit code of --strict
+var dependencyErr error
+
+// recordedRenames are the directories and files moved by the install steps, used to report renames
+var recordedRenames []struct{ From, To string }
+
+// currentReport is the report of the running installation, nil without --report
+var currentReport *installReport
+
+// recordRename remembers that a file or directory was moved
+func recordRename(from, to string) {
+	recordedRenames = append(recordedRenames, struct{ From, To string }{from, to})
+}
+
+// finishReport writes the report of the running installation, if any
+func finishReport(results []stepResult, runErr error, cancelled bool) {
+	if currentReport == nil {
+		return
+	}
+
+	report := currentReport
+	currentReport = nil
+	if err := report.finish(results, runErr, cancelled); err != nil {
+		warn("", fmt.Sprintf("Failed to write the installation report: %v", err))
+	}
+}
+
+// startReport takes a snapshot of the project directory before the steps run.
+// Without a report path it returns nil.
+func startReport(config *Config, jsonPath, markdownPath string) (*installReport, error) {
+	if jsonPath == "" && markdownPath == "" {
+		return nil, nil
+	}
+
+	before, err := snapshotFiles(config.ProjectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", config.ProjectDir, err)
+	}
+
+	return &installReport{
+		Version:      reportVersion,
+		Template:     templateSource,
+		StartedAt:    time.Now().UTC(),
+		Config:       config,
+		jsonPath:     jsonPath,
+		markdownPath: markdownPath,
+		before:       before,
+	}, nil
+}
+
+// finish completes the report with the results of the run and writes it
+func (r *installReport) finish(results []stepResult, runErr error, cancelled bool) error {
+	r.FinishedAt = time.Now().UTC()
+	r.Duration = r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond).String()
+
+	switch {
+	case cancelled:
+		r.Result = "cancelled"
+	case runErr != nil:
+		r.Result = "failed"
+	default:
+		r.Result = "success"
+	}
+	if runErr != nil {
+		r.Error = runErr.Error()
+		r.Hint = hintOf(runErr)
+		r.ExitCode = exitCodeOf(runErr)
+	}
+	if cancelled {
+		r.ExitCode = exitCodeInterrupted
+	}
+	r.Commit = templateCommit
+
+	r.Steps = make([]reportStep, 0, len(results))
+	for _, result := range results {
+		step := reportStep{Name: result.Name, Status: result.Status}
+		if result.Status != "skipped" {
+			step.Duration = result.Duration.Round(time.Millisecond).String()
+		}
+		r.Steps = append(r.Steps, step)
+	}
+
+	after, err := snapshotFiles(r.Config.ProjectDir)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", r.Config.ProjectDir, err)
+	}
+	r.Files = diffSnapshots(r.Config.ProjectDir, r.before, after)
+	r.Libraries = resolveLibraryVersions(r.Config)
+	r.Warnings = append([]reportWarning{}, warnings...)
+
+	if r.jsonPath != "" {
+		content, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(r.jsonPath, append(content, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", r.jsonPath, err)
+		}
+	}
+
+	if r.markdownPath != "" {
+		if err := os.WriteFile(r.markdownPath, []byte(r.markdown()), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", r.markdownPath, err)
+		}
+	}
+	return nil
+}
+
+// markdown renders the report as a Markdown document
+func (r *installReport) markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# WebCore Installation Report\n\n")
+	fmt.Fprintf(&b, "- **Result:** %s\n", r.Result)
+	if r.Error != "" {
+		fmt.Fprintf(&b, "- **Error:** %s\n", r.Error)
+	}
+	if r.Hint != "" {
+		fmt.Fprintf(&b, "- **Hint:** %s\n", r.Hint)
+	}
+	fmt.Fprintf(&b, "- **Project directory:** `%s`\n", r.Config.ProjectDir)
+	fmt.Fprintf(&b, "- **Module:** `%s`\n", r.Config.ModuleName)
+	fmt.Fprintf(&b, "- **Mode:** %s\n", r.Config.ProjectMode)
+	fmt.Fprintf(&b, "- **Template:** %s%s\n", r.Template, templateCommitSuffix())
+	fmt.Fprintf(&b, "- **Started:** %s\n", r.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- **Duration:** %s\n", r.Duration)
+
+	fmt.Fprintf(&b, "\n## Steps\n\n| Step | Status | Duration |\n|------|--------|----------|\n")
+	for _, step := range r.Steps {
+		duration := step.Duration
+		if duration == "" {
+			duration = "-"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", step.Name, step.Status, duration)
+	}
+
+	fmt.Fprintf(&b, "\n## Files\n\n")
+	if len(r.Files) == 0 {
+		fmt.Fprintf(&b, "No files changed.\n")
+	} else {
+		fmt.Fprintf(&b, "| Change | Path |\n|--------|------|\n")
+		for _, file := range r.Files {
+			change, path := file.Change, fmt.Sprintf("`%s`", file.Path)
+			if file.From != "" {
+				path = fmt.Sprintf("`%s` → `%s`", file.From, file.Path)
+			}
+			if file.Modified {
+				change += ", modified"
+			}
+			fmt.Fprintf(&b, "| %s | %s |\n", change, path)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## Libraries\n\n| Library | Module | Version |\n|---------|--------|---------|\n")
+	for _, lib := range r.Libraries {
+		module, version := "-", "not resolved"
+		if lib.Module != "" {
+			module, version = fmt.Sprintf("`%s`", lib.Module), lib.Version
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", lib.Name, module, version)
+	}
+
+	fmt.Fprintf(&b, "\n## Warnings\n\n")
+	if len(r.Warnings) == 0 {
+		fmt.Fprintf(&b, "No warnings.\n")
+	}
+	for _, warning := range r.Warnings {
+		if warning.Step != "" {
+			fmt.Fprintf(&b, "- `%s`: %s\n", warning.Step, warning.Message)
+			continue
+		}
+		fmt.Fprintf(&b, "- %s\n", warning.Message)
+	}
+	return b.String()
+}
+
+// snapshotFiles hashes every file of the project directory, the .git directory and the checkpoint are ignored
+func snapshotFiles(root string) (fileSnapshot, error) {
+	snapshot := make(fileSnapshot)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || info.Name() == checkpointFileName {
+			return nil
+		}
+
+		hash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		snapshot[filepath.ToSlash(rel)] = hash
+		return nil
+	})
+	return snapshot, err
+}
+
+// hashFile returns the SHA-256 of the file content
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// diffSnapshots lists the changes between two snapshots.
+// A deleted file whose path was moved by a recorded rename and exists at the new path is reported as renamed.
+func diffSnapshots(root string, before, after fileSnapshot) []fileChange {
+	changes := make([]fileChange, 0)
+	renamedTo := make(map[string]bool)
+
+	for path, hash := range before {
+		if newHash, ok := after[path]; ok {
+			if newHash != hash {
+				changes = append(changes, fileChange{Path: path, Change: "modified"})
+			}
+			continue
+		}
+
+		if newPath := renamedPath(root, path); newPath != "" {
+			if newHash, ok := after[newPath]; ok {
+				changes = append(changes, fileChange{Path: newPath, Change: "renamed", From: path, Modified: newHash != hash})
+				renamedTo[newPath] = true
+				continue
+			}
+		}
+		changes = append(changes, fileChange{Path: path, Change: "deleted"})
+	}
+
+	for path := range after {
+		if _, ok := before[path]; !ok && !renamedTo[path] {
+			changes = append(changes, fileChange{Path: path, Change: "created"})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// renamedPath returns the new path of a file inside a recorded rename, relative to root
+func renamedPath(root, path string) string {
+	for _, rename := range recordedRenames {
+		from, err1 := filepath.Rel(root, rename.From)
+		to, err2 := filepath.Rel(root, rename.To)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		from, to = filepath.ToSlash(from), filepath.ToSlash(to)
+
+		if path == from {
+			return to
+		}
+		if strings.HasPrefix(path, from+"/") {
+			return to + strings.TrimPrefix(path, from)
+		}
+	}
+	return ""
+}
+
+// resolveLibraryVersions looks up the module and version of every selected library in webcore/go.mod
+func resolveLibraryVersions(config *Config) []reportLibrary {
+	var requires []*modfile.Require
+	goModPath := filepath.Join(config.ProjectDir, "webcore", "go.mod")
+	if content, err := os.ReadFile(goModPath); err == nil {
+		if f, err := modfile.Parse(goModPath, content, nil); err == nil {
+			requires = f.Require
+		}
+	}
+
+	libraries := make([]reportLibrary, 0, len(config.SelectedLibraries))
+	for _, lib := range config.SelectedLibraries {
+		entry := reportLibrary{Name: lib.Name, PackagePath: lib.PackagePath}
+
+		// The longest module path containing the package wins
+		for _, req := range requires {
+			if (lib.PackagePath == req.Mod.Path || strings.HasPrefix(lib.PackagePath, req.Mod.Path+"/")) && len(req.Mod.Path) > len(entry.Module) {
+				entry.Module, entry.Version = req.Mod.Path, req.Mod.Version
+			}
+		}
+		libraries = append(libraries, entry)
+	}
+	return libraries
+}
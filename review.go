@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yarlson/tap"
+)
+
+const (
+	reviewConfirm = "confirm"
+	reviewCancel  = "cancel"
+)
+
+// reviewFields are the answers that can be edited from the review screen, in prompt order
+var reviewFields = []struct {
+	Field string
+	Label string
+}{
+	{"module", "Module name"},
+	{"libraries", "Libraries"},
+	{"service-config", "Service settings"},
+	{"mode", "Project mode"},
+	{"folder", "Module folder"},
+	{"package", "Package name"},
+	{"module-mod", "Module go.mod name"},
+	{"features", "Features"},
+	{"git", "Initialize git"},
+	{"git-branch", "Git branch"},
+	{"git-remote", "Git remote origin"},
+	{"git-commit", "Initial commit"},
+	{"dockerfile", "Dockerfile"},
+	{"ci", "CI pipeline"},
+	{"kubernetes", "Kubernetes"},
+}
+
+// reviewConfig shows a summary of the answers and lets the user confirm, edit an answer or cancel.
+// editable limits the fields that can be edited, nil allows all of them.
+// When cloned is set the template was downloaded by this run and may be removed on cancel.
+// The hooks are shown below the answers, they can't be edited.
+func reviewConfig(ctx context.Context, config *Config, hooks []Hook, editable []string, cloned *clonedTemplate) error {
+	for {
+		showConfigSummary(config, hooks)
+
+		if nonInteractive {
+			return nil
+		}
+
+		options := []tap.SelectOption[string]{{Label: "Confirm and apply", Value: reviewConfirm}}
+		for _, f := range reviewFields {
+			if !isReviewFieldShown(config, f.Field) || !isEditable(editable, f.Field) {
+				continue
+			}
+			options = append(options, tap.SelectOption[string]{Label: "Edit " + strings.ToLower(f.Label), Value: f.Field})
+		}
+		options = append(options, tap.SelectOption[string]{Label: "Cancel installation", Value: reviewCancel})
+
+		action := promptSelect(ctx, tap.SelectOptions[string]{
+			Message:      "Apply this configuration?",
+			Options:      options,
+			InitialValue: &options[0].Value,
+		})
+
+		switch action {
+		case reviewConfirm:
+			return nil
+		case reviewCancel:
+			return cancelInstallation(ctx, config, cloned)
+		}
+
+		if err := askField(ctx, config, action); err != nil {
+			return err
+		}
+	}
+}
+
+// showConfigSummary prints the answers and hooks as a table
+func showConfigSummary(config *Config, hooks []Hook) {
+	rows := [][]string{{"Project directory", config.ProjectDir, answerSource("dir")}}
+	for _, f := range reviewFields {
+		if !isReviewFieldShown(config, f.Field) {
+			continue
+		}
+		// One row per line so multi-line values stay inside the table
+		for i, line := range strings.Split(reviewFieldValue(config, f.Field), "\n") {
+			label, source := f.Label, answerSource(f.Field)
+			if i > 0 {
+				label, source = "", ""
+			}
+			rows = append(rows, []string{label, line, source})
+		}
+	}
+	if len(hooks) > 0 {
+		for i, line := range strings.Split(hooksSummary(hooks), "\n") {
+			label := ""
+			if i == 0 {
+				label = "Hooks"
+			}
+			rows = append(rows, []string{label, line, ""})
+		}
+	}
+
+	showTable([]string{"Setting", "Value", "Source"}, rows, tap.TableOptions{ShowBorders: true, IncludePrefix: true, MaxWidth: 120})
+}
+
+// isReviewFieldShown hides the mono-repo answers in simple mode
+func isReviewFieldShown(config *Config, field string) bool {
+	switch field {
+	case "folder", "package", "module-mod":
+		return config.ProjectMode == "mono-repo"
+	case "git-branch", "git-remote", "git-commit":
+		return config.GitInit
+	case "service-config":
+		return len(selectedServiceSettings(config.SelectedLibraries)) > 0
+	}
+	return true
+}
+
+// reviewFieldValue formats the current answer of a field
+func reviewFieldValue(config *Config, field string) string {
+	switch field {
+	case "module":
+		return config.ModuleName
+	case "libraries":
+		libs := make([]string, len(config.SelectedLibraries))
+		for i, lib := range config.SelectedLibraries {
+			libs[i] = fmt.Sprintf("%s (%s)", lib.Name, lib.PackagePath)
+		}
+		return noneIfEmpty(strings.Join(libs, "\n"))
+	case "mode":
+		return config.ProjectMode
+	case "folder":
+		return config.FolderName
+	case "package":
+		return config.PackageName
+	case "module-mod":
+		return config.ModuleModName
+	case "features":
+		features := make([]string, len(config.SelectedFeatures))
+		for i, feature := range config.SelectedFeatures {
+			features[i] = feature.Name
+		}
+		return noneIfEmpty(strings.Join(features, ", "))
+	case "git":
+		if config.GitInit {
+			return "yes"
+		}
+		return "no"
+	case "git-branch":
+		return config.GitBranch
+	case "git-remote":
+		return noneIfEmpty(config.GitRemote)
+	case "git-commit":
+		if !config.GitCommit {
+			return "no"
+		}
+		message := config.GitCommitMessage
+		if message == "" {
+			message = defaultGitCommitMessage
+		}
+		if config.GitAuthor != "" {
+			return fmt.Sprintf("yes, %q by %s", message, config.GitAuthor)
+		}
+		return fmt.Sprintf("yes, %q", message)
+	case "dockerfile":
+		if config.Dockerfile {
+			return "yes"
+		}
+		return "no"
+	case "ci":
+		if config.CI == "" {
+			return ciNone
+		}
+		return fmt.Sprintf("%s (%s)", config.CI, ciFiles[config.CI])
+	case "service-config":
+		return serviceSettingsSummary(config)
+	case "kubernetes":
+		if config.Kubernetes == "" {
+			return k8sNone
+		}
+		return fmt.Sprintf("%s (%s)", config.Kubernetes, k8sDir)
+	}
+	return ""
+}
+
+// noneIfEmpty returns "none" for an empty value
+func noneIfEmpty(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// isEditable checks if field is in the editable list, a nil list allows every field
+func isEditable(editable []string, field string) bool {
+	if editable == nil {
+		return true
+	}
+	for _, item := range editable {
+		if item == field {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelInstallation offers to remove a template downloaded by this run and returns the cancel error
+func cancelInstallation(ctx context.Context, config *Config, cloned *clonedTemplate) error {
+	if cloned == nil {
+		return newInstallError(exitCodeInterrupted, "", fmt.Errorf("installation cancelled, %s was left unchanged", config.ProjectDir))
+	}
+
+	remove := promptConfirm(ctx, tap.ConfirmOptions{
+		Message:      fmt.Sprintf("Remove the downloaded template in %s?", config.ProjectDir),
+		InitialValue: true,
+	})
+	if !remove {
+		return newInstallError(exitCodeInterrupted, "", fmt.Errorf("installation cancelled, the downloaded template was left in %s", config.ProjectDir))
+	}
+
+	if err := cloned.remove(); err != nil {
+		return newInstallError(exitCodeInterrupted, "", fmt.Errorf("installation cancelled, failed to remove the template from %s: %w", config.ProjectDir, err))
+	}
+	return newInstallError(exitCodeInterrupted, "", fmt.Errorf("installation cancelled, the downloaded template was removed from %s", config.ProjectDir))
+}
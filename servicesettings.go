@@ -0,0 +1,701 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yarlson/tap"
+	"gopkg.in/yaml.v3"
+)
+
+const envFileName = ".env"
+
+// serviceSetting is a connection setting of a config.yaml section that the service settings questions ask for
+type serviceSetting struct {
+	Section  string
+	Key      string
+	Aliases  []string // other keys the template may use for the setting
+	Label    string
+	Default  string // answer when config.yaml.example doesn't set it, ports default to the port of the service
+	Secret   bool   // asked without echo and optionally written to .env
+	List     bool   // comma separated answer, written as a list
+	Validate func(string) error
+}
+
+// serviceSettings is the catalog of the settings asked for the config.yaml sections of the selected libraries
+var serviceSettings = []serviceSetting{
+	{Section: "database", Key: "host", Label: "Database host", Default: "localhost"},
+	{Section: "database", Key: "port", Label: "Database port", Validate: validatePort},
+	{Section: "database", Key: "name", Aliases: []string{"dbname", "database"}, Label: "Database name", Default: "webcore"},
+	{Section: "database", Key: "user", Aliases: []string{"username"}, Label: "Database user", Default: "webcore"},
+	{Section: "database", Key: "password", Label: "Database password", Default: "webcore", Secret: true},
+	{Section: "redis", Key: "address", Aliases: []string{"addr"}, Label: "Redis address", Default: "localhost:6379", Validate: validateAddress},
+	{Section: "kafka", Key: "brokers", Label: "Kafka brokers", Default: "localhost:9092", List: true, Validate: validateAddressList},
+	{Section: "kafka", Key: "topics", Label: "Kafka topics", Default: "events", List: true},
+	{Section: "pubsub", Key: "project_id", Aliases: []string{"project"}, Label: "Pub/Sub project ID", Default: "local-project"},
+}
+
+// ID returns the section and key of the setting, e.g. database.host
+func (s serviceSetting) ID() string {
+	return s.Section + "." + s.Key
+}
+
+// EnvName returns the name of the environment variable of the setting, e.g. DATABASE_PASSWORD
+func (s serviceSetting) EnvName() string {
+	return settingEnvName(s.Section, s.Key)
+}
+
+// settingEnvName returns the name of the environment variable that overrides a config.yaml setting
+func settingEnvName(section, key string) string {
+	return strings.ToUpper(section + "_" + key)
+}
+
+// selectedServiceSettings returns the settings of the config.yaml sections of the selected libraries
+func selectedServiceSettings(libraries []LibraryOption) []serviceSetting {
+	sections := make(map[string]bool)
+	for _, service := range selectedBackingServices(libraries) {
+		sections[service.Section] = true
+	}
+
+	settings := make([]serviceSetting, 0)
+	for _, setting := range serviceSettings {
+		if sections[setting.Section] {
+			settings = append(settings, setting)
+		}
+	}
+	return settings
+}
+
+// askServiceSettings asks for the connection settings of the selected libraries and whether the secrets go to .env.
+// The answers are prefilled with the previous answers or the values of config.yaml.example.
+func askServiceSettings(ctx context.Context, config *Config) error {
+	settings := selectedServiceSettings(config.SelectedLibraries)
+	if len(settings) == 0 {
+		config.ServiceConfig = false
+		return nil
+	}
+
+	configure := promptConfirm(ctx, tap.ConfirmOptions{
+		Message:      withSource("Configure the services of the selected libraries in config.yaml?", "service-config"),
+		InitialValue: config.ServiceConfig,
+	})
+	config.ServiceConfig = configure
+	if !configure {
+		showMessage("⏭️ config.yaml keeps the example settings")
+		return nil
+	}
+
+	example, err := readSettingsFile(filepath.Join(config.ProjectDir, "config.yaml.example"))
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]string)
+	secrets := make(map[string]string)
+	for _, setting := range settings {
+		current, ok := config.ServiceSettings[setting.ID()]
+		if setting.Secret {
+			current, ok = config.ServiceSecrets[setting.ID()]
+		}
+		if !ok {
+			current = exampleServiceSetting(example, setting, config.SelectedLibraries)
+		}
+
+		value, err := askServiceSetting(ctx, setting, current)
+		if err != nil {
+			return err
+		}
+		if setting.Secret {
+			secrets[setting.ID()] = value
+		} else {
+			values[setting.ID()] = value
+		}
+	}
+	config.ServiceSettings, config.ServiceSecrets = values, secrets
+
+	if len(secrets) > 0 {
+		config.SecretsEnvFile = promptConfirm(ctx, tap.ConfirmOptions{
+			Message:      withSource(fmt.Sprintf("Put the secrets into %s instead of config.yaml?", envFileName), "env-file"),
+			InitialValue: config.SecretsEnvFile,
+		})
+	} else {
+		config.SecretsEnvFile = false
+	}
+
+	showMessage(fmt.Sprintf("✅ Service settings: %s\n", serviceSettingsSummary(config)))
+	return nil
+}
+
+// askServiceSecrets asks again for the secrets of the service settings when resuming,
+// they are never written to the checkpoint. Secrets already in .env are taken from there.
+func askServiceSecrets(ctx context.Context, config *Config) error {
+	example, err := readSettingsFile(filepath.Join(config.ProjectDir, "config.yaml.example"))
+	if err != nil {
+		return err
+	}
+	envValues, err := readEnvFile(filepath.Join(config.ProjectDir, envFileName))
+	if err != nil {
+		return err
+	}
+
+	if config.ServiceSecrets == nil {
+		config.ServiceSecrets = make(map[string]string)
+	}
+	for _, setting := range selectedServiceSettings(config.SelectedLibraries) {
+		if _, ok := config.ServiceSecrets[setting.ID()]; ok || !setting.Secret {
+			continue
+		}
+		if value, ok := envValues[setting.EnvName()]; ok && config.SecretsEnvFile && value != "" {
+			config.ServiceSecrets[setting.ID()] = value
+			continue
+		}
+
+		value, err := askServiceSetting(ctx, setting, exampleServiceSetting(example, setting, config.SelectedLibraries))
+		if err != nil {
+			return err
+		}
+		config.ServiceSecrets[setting.ID()] = value
+	}
+	return nil
+}
+
+// exampleServiceSetting returns the value of a setting in config.yaml.example, or its default
+func exampleServiceSetting(example map[string]any, setting serviceSetting, libraries []LibraryOption) string {
+	section, _ := example[setting.Section].(map[string]any)
+	if value := firstSetting(section, append([]string{setting.Key}, setting.Aliases...)...); value != "" {
+		return value
+	}
+	return defaultServiceSetting(setting, libraries)
+}
+
+// defaultServiceSetting returns the default of a setting, the port of the selected service for ports
+func defaultServiceSetting(setting serviceSetting, libraries []LibraryOption) string {
+	if setting.Key != "port" {
+		return setting.Default
+	}
+	for _, service := range selectedBackingServices(libraries) {
+		if service.Section == setting.Section {
+			return strconv.Itoa(service.Port)
+		}
+	}
+	return setting.Default
+}
+
+// askServiceSetting asks for a single setting until the answer is valid
+func askServiceSetting(ctx context.Context, setting serviceSetting, current string) (string, error) {
+	message := fmt.Sprintf("Enter the %s", strings.ToLower(setting.Label[:1])+setting.Label[1:])
+	if setting.List {
+		message += " (comma separated)"
+	}
+
+	for {
+		var value string
+		if setting.Secret {
+			value = promptPassword(ctx, tap.PasswordOptions{Message: message, InitialValue: current})
+		} else {
+			value = promptText(ctx, tap.TextOptions{Message: message, Placeholder: current, InitialValue: current})
+		}
+		if setting.List {
+			value = strings.Join(splitList(value), ",")
+		}
+
+		err := errors.New("a value is required")
+		if value != "" {
+			err = nil
+			if setting.Validate != nil {
+				err = setting.Validate(value)
+			}
+		}
+		if err != nil {
+			if nonInteractive {
+				return "", invalidInputError(fmt.Sprintf("fix %s in config.yaml.example or answer the questions", setting.ID()), fmt.Errorf("invalid %s %q: %w", strings.ToLower(setting.Label), value, err))
+			}
+			showMessage(fmt.Sprintf("❌ Invalid %s: %v", strings.ToLower(setting.Label), err))
+			continue
+		}
+		return value, nil
+	}
+}
+
+// validatePort checks that value is a TCP port
+func validatePort(value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return errors.New("expected a port between 1 and 65535")
+	}
+	return nil
+}
+
+// validateAddress checks that value is a host:port address
+func validateAddress(value string) error {
+	_, port, err := net.SplitHostPort(value)
+	if err != nil {
+		return errors.New("expected host:port")
+	}
+	return validatePort(port)
+}
+
+// validateAddressList checks that value is a comma separated list of host:port addresses
+func validateAddressList(value string) error {
+	for _, address := range splitList(value) {
+		if err := validateAddress(address); err != nil {
+			return fmt.Errorf("%s: %w", address, err)
+		}
+	}
+	return nil
+}
+
+// serviceSettingsSummary returns the service settings for the review, secrets are masked
+func serviceSettingsSummary(config *Config) string {
+	if !config.ServiceConfig {
+		return "no"
+	}
+
+	parts := make([]string, 0)
+	for _, setting := range selectedServiceSettings(config.SelectedLibraries) {
+		if value, ok := config.ServiceSettings[setting.ID()]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%s", setting.ID(), value))
+		} else if _, ok := config.ServiceSecrets[setting.ID()]; ok {
+			parts = append(parts, fmt.Sprintf("%s=********", setting.ID()))
+		}
+	}
+	summary := strings.Join(parts, ", ")
+	if config.SecretsEnvFile {
+		summary += fmt.Sprintf(" (secrets in %s)", envFileName)
+	}
+	return summary
+}
+
+// lineEdit replaces the text of a value in a line of config.yaml
+type lineEdit struct {
+	Line       int // 1-based line number
+	Start, End int // byte offsets of the value in the line
+	Text       string
+	Remove     bool // whether the whole line is removed, e.g. a list item that is gone
+}
+
+// applyServiceSettings writes the service settings into config.yaml, keeping its comments and layout.
+// Values and list items are replaced in place, only settings the file doesn't have re-encode it, with a warning.
+// Secrets go to .env when requested, their config.yaml value is then emptied.
+func applyServiceSettings(config *Config) error {
+	configPath := filepath.Join(config.ProjectDir, "config.yaml")
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config.yaml: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("failed to parse config.yaml: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return errors.New("config.yaml is not a mapping")
+	}
+	root := doc.Content[0]
+	lines := strings.Split(string(content), "\n")
+
+	edits := make([]lineEdit, 0)
+	reencoded := make([]string, 0)
+	envValues := make(map[string]string)
+	for _, setting := range selectedServiceSettings(config.SelectedLibraries) {
+		value, ok := config.ServiceSettings[setting.ID()]
+		if setting.Secret {
+			// Secrets aren't kept in the checkpoint, a resumed run asks for them again
+			if value, ok = config.ServiceSecrets[setting.ID()]; ok && config.SecretsEnvFile {
+				envValues[setting.EnvName()] = value
+				value = ""
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		section := mappingValue(root, setting.Section)
+		if section == nil {
+			section = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: setting.Section}, section)
+		}
+		node, settingEdits, ok := setSetting(section, setting, value, lines)
+		if value == "" && config.SecretsEnvFile && node.LineComment == "" {
+			node.LineComment = fmt.Sprintf("# set by %s in %s", setting.EnvName(), envFileName)
+			if ok {
+				settingEdits[0].Text += " " + node.LineComment
+			}
+		}
+		if ok {
+			edits = append(edits, settingEdits...)
+		} else {
+			reencoded = append(reencoded, setting.ID())
+		}
+	}
+
+	out := []byte(applyLineEdits(lines, edits))
+	if len(reencoded) > 0 {
+		warn("service-settings", fmt.Sprintf("config.yaml has no editable %s, the file was rewritten and may have lost comments and formatting", strings.Join(reencoded, ", ")))
+		if out, err = marshalYAML("", &doc); err != nil {
+			return fmt.Errorf("failed to encode config.yaml: %w", err)
+		}
+	}
+	if err := os.WriteFile(configPath, out, 0644); err != nil {
+		return fmt.Errorf("failed to write config.yaml: %w", err)
+	}
+
+	if len(envValues) > 0 {
+		if err := writeEnvFile(filepath.Join(config.ProjectDir, envFileName), envValues); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isServiceSettingsDone detects that the non-secret service settings are already in config.yaml
+func isServiceSettingsDone(config *Config) bool {
+	if !config.ServiceConfig {
+		return true
+	}
+	settings, err := readConfigYAML(config.ProjectDir)
+	if err != nil || settings == nil {
+		return false
+	}
+	for _, setting := range selectedServiceSettings(config.SelectedLibraries) {
+		value, ok := config.ServiceSettings[setting.ID()]
+		if !ok {
+			continue
+		}
+		section, _ := settings[setting.Section].(map[string]any)
+		if firstSetting(section, append([]string{setting.Key}, setting.Aliases...)...) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// settingKey returns the key of a setting in a section, the first alias that exists or the setting key
+func settingKey(section *yaml.Node, setting serviceSetting) string {
+	for _, key := range append([]string{setting.Key}, setting.Aliases...) {
+		if mappingValue(section, key) != nil {
+			return key
+		}
+	}
+	return setting.Key
+}
+
+// setSetting sets the value of a setting in a section node and returns the value node.
+// The edits replace the existing value in the lines of the file, ok is false when the file has to be re-encoded.
+// Lists keep the style of the example, a list or a comma separated string.
+func setSetting(section *yaml.Node, setting serviceSetting, value string, lines []string) (*yaml.Node, []lineEdit, bool) {
+	key := settingKey(section, setting)
+	node := mappingValue(section, key)
+	if node == nil {
+		node = &yaml.Node{}
+		section.Content = append(section.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, node)
+	}
+
+	if setting.List && (node.Kind == yaml.SequenceNode || node.Kind == 0) {
+		edits, ok := sequenceEdits(lines, node, splitList(value))
+		items := make([]*yaml.Node, 0)
+		for _, item := range splitList(value) {
+			items = append(items, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+		}
+		node.Kind, node.Tag, node.Value, node.Content = yaml.SequenceNode, "!!seq", "", items
+		return node, edits, ok
+	}
+
+	start, end, found := scalarSpan(lines, node)
+
+	// Numbers stay numbers, e.g. the port, other values are written as strings
+	tag := "!!str"
+	if _, err := strconv.Atoi(value); err == nil && (node.Tag == "!!int" || key == "port") {
+		tag = "!!int"
+	}
+	node.Kind, node.Tag, node.Value, node.Content, node.Style = yaml.ScalarNode, tag, value, nil, 0
+	if !found {
+		return node, nil, false
+	}
+	return node, []lineEdit{{Line: node.Line, Start: start, End: end, Text: scalarText(tag, value)}}, true
+}
+
+// sequenceEdits returns the edits that replace the items of a list in the lines of the file.
+// Flow lists are rewritten on their line, block lists item by item, extra items are added after the last one.
+func sequenceEdits(lines []string, node *yaml.Node, items []string) ([]lineEdit, bool) {
+	if node.Kind != yaml.SequenceNode || !isScalarList(node) {
+		return nil, false
+	}
+
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = scalarText("!!str", item)
+	}
+
+	if node.Style == yaml.FlowStyle {
+		start, end, found := flowSpan(lines, node)
+		if !found {
+			return nil, false
+		}
+		return []lineEdit{{Line: node.Line, Start: start, End: end, Text: "[" + strings.Join(texts, ", ") + "]"}}, true
+	}
+
+	// Every block item has to be a single line scalar on its own line
+	if len(node.Content) == 0 {
+		return nil, false
+	}
+	edits := make([]lineEdit, 0)
+	for i, item := range node.Content {
+		start, end, found := scalarSpan(lines, item)
+		if !found || (i > 0 && item.Line == node.Content[i-1].Line) {
+			return nil, false
+		}
+		switch {
+		case i >= len(texts):
+			edits = append(edits, lineEdit{Line: item.Line, Remove: true})
+		case i == len(node.Content)-1:
+			// The last item takes the items that are new, with the indentation of the list
+			prefix := lines[item.Line-1][:start]
+			edits = append(edits, lineEdit{Line: item.Line, Start: start, End: end, Text: strings.Join(texts[i:], "\n"+prefix)})
+		default:
+			edits = append(edits, lineEdit{Line: item.Line, Start: start, End: end, Text: texts[i]})
+		}
+	}
+	return edits, len(texts) > 0
+}
+
+// flowSpan returns the byte offsets of a single line flow list, [a, b], in its line of the file
+func flowSpan(lines []string, node *yaml.Node) (int, int, bool) {
+	if node.Line < 1 || node.Line > len(lines) {
+		return 0, 0, false
+	}
+	line := lines[node.Line-1]
+	start := columnOffset(line, node.Column)
+	if start < 0 || line[start] != '[' {
+		return 0, 0, false
+	}
+
+	quote := byte(0)
+	for i := start + 1; i < len(line); i++ {
+		switch {
+		case quote == '"' && line[i] == '\\':
+			i++
+		case quote != 0 && line[i] == quote:
+			quote = 0
+		case quote == 0 && (line[i] == '"' || line[i] == '\''):
+			quote = line[i]
+		case quote == 0 && line[i] == ']':
+			return start, i + 1, true
+		}
+	}
+	return 0, 0, false
+}
+
+// scalarText returns a value encoded as YAML scalar, quoted when needed
+func scalarText(tag, value string) string {
+	text, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})
+	if err != nil {
+		return strconv.Quote(value)
+	}
+	return strings.TrimSuffix(string(text), "\n")
+}
+
+// scalarSpan returns the byte offsets of a single line scalar in its line of the file
+func scalarSpan(lines []string, node *yaml.Node) (int, int, bool) {
+	if node.Kind != yaml.ScalarNode || node.Line < 1 || node.Line > len(lines) {
+		return 0, 0, false
+	}
+	line := lines[node.Line-1]
+
+	start := columnOffset(line, node.Column)
+	if start < 0 {
+		return 0, 0, false
+	}
+
+	switch node.Style {
+	case yaml.DoubleQuotedStyle:
+		for i := start + 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return start, i + 1, true
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] != '\'' {
+				continue
+			}
+			if i+1 < len(line) && line[i+1] == '\'' {
+				i++
+				continue
+			}
+			return start, i + 1, true
+		}
+	case 0:
+		end := len(line)
+		if i := strings.Index(line[start:], " #"); i >= 0 {
+			end = start + i
+		}
+		end = start + len(strings.TrimRight(line[start:end], " \t\r"))
+		// Multi-line plain scalars are re-encoded
+		if line[start:end] == node.Value {
+			return start, end, true
+		}
+	}
+	return 0, 0, false
+}
+
+// columnOffset returns the byte offset of a 1-based column in a line, -1 when the line is shorter.
+// The column counts characters, not bytes.
+func columnOffset(line string, column int) int {
+	current := 1
+	for i := range line {
+		if current == column {
+			return i
+		}
+		current++
+	}
+	return -1
+}
+
+// applyLineEdits applies the edits to the lines of a file, later edits of a line first
+func applyLineEdits(lines []string, edits []lineEdit) string {
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].Line != edits[j].Line {
+			return edits[i].Line < edits[j].Line
+		}
+		return edits[i].Start > edits[j].Start
+	})
+
+	out := make([]string, len(lines))
+	copy(out, lines)
+	removed := make(map[int]bool)
+	for _, edit := range edits {
+		if edit.Remove {
+			removed[edit.Line] = true
+			continue
+		}
+		line := out[edit.Line-1]
+		out[edit.Line-1] = line[:edit.Start] + edit.Text + line[edit.End:]
+	}
+
+	kept := make([]string, 0, len(out))
+	for i, line := range out {
+		if !removed[i+1] {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// mappingValue returns the value node of key in a mapping node, nil when it doesn't exist
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// writeEnvFile sets values in a .env file with mode 0600, other lines of an existing file are kept
+func writeEnvFile(path string, values map[string]string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", envFileName, err)
+	}
+
+	lines := make([]string, 0)
+	written := make(map[string]bool)
+	if len(content) > 0 {
+		for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+			name, _, _ := strings.Cut(line, "=")
+			if value, ok := values[strings.TrimSpace(name)]; ok {
+				line = fmt.Sprintf("%s=%s", strings.TrimSpace(name), strconv.Quote(value))
+				written[strings.TrimSpace(name)] = true
+			}
+			lines = append(lines, line)
+		}
+	}
+	for _, name := range sortedKeys(values) {
+		if !written[name] {
+			lines = append(lines, fmt.Sprintf("%s=%s", name, strconv.Quote(values[name])))
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", envFileName, err)
+	}
+	return os.Chmod(path, 0600)
+}
+
+// readEnvFile reads the variables of a .env file, nil when it doesn't exist
+func readEnvFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", envFileName, err)
+	}
+
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		values[strings.TrimSpace(name)] = value
+	}
+	return values, nil
+}
+
+// applyEnvFileReferences replaces the secret settings that are set in .env with compose interpolation references,
+// e.g. ${DATABASE_PASSWORD}, so the secrets never get into docker-compose.yaml.
+// It returns the sections that reference .env.
+func applyEnvFileReferences(projectDir string, settings map[string]any) (map[string]bool, error) {
+	values, err := readEnvFile(filepath.Join(projectDir, envFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	sections := make(map[string]bool)
+	for _, setting := range serviceSettings {
+		if _, ok := values[setting.EnvName()]; !ok || !setting.Secret {
+			continue
+		}
+		section, _ := settings[setting.Section].(map[string]any)
+		if section == nil {
+			section = map[string]any{}
+			settings[setting.Section] = section
+		}
+		section[settingKeyOf(section, setting)] = "${" + setting.EnvName() + "}"
+		sections[setting.Section] = true
+	}
+	return sections, nil
+}
+
+// settingKeyOf returns the key of a setting in a parsed section, the first alias that is set or the setting key
+func settingKeyOf(section map[string]any, setting serviceSetting) string {
+	for _, key := range append([]string{setting.Key}, setting.Aliases...) {
+		if _, ok := section[key]; ok {
+			return key
+		}
+	}
+	return setting.Key
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// valueNode parses content and returns the node at the keys of path
+func valueNode(t *testing.T, content string, path ...string) *yaml.Node {
+	t.Helper()
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		t.Fatal(err)
+	}
+	node := doc.Content[0]
+	for _, key := range path {
+		node = mappingValue(node, key)
+		if node == nil {
+			t.Fatalf("%s not found in %q", key, content)
+		}
+	}
+	return node
+}
+
+func TestScalarSpan(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		path    []string
+		want    string // text of the span, none when it isn't found
+	}{
+		{name: "plain", content: "database:\n  host: localhost\n", path: []string{"database", "host"}, want: "localhost"},
+		{name: "plain with comment", content: "host: localhost  # local\n", path: []string{"host"}, want: "localhost"},
+		{name: "plain with spaces", content: "name: my app \n", path: []string{"name"}, want: "my app"},
+		{name: "hash in value", content: "password: a#b\n", path: []string{"password"}, want: "a#b"},
+		{name: "double quoted", content: `password: "a\"b # c" # note` + "\n", path: []string{"password"}, want: `"a\"b # c"`},
+		{name: "single quoted", content: "password: 'it''s' # note\n", path: []string{"password"}, want: "'it''s'"},
+		{name: "multi-byte key", content: "größe: 10\n", path: []string{"größe"}, want: "10"},
+		{name: "multi-line plain", content: "name: first\n  second\n", path: []string{"name"}},
+		{name: "literal block", content: "name: |\n  text\n", path: []string{"name"}},
+		{name: "not a scalar", content: "brokers:\n  - a\n", path: []string{"brokers"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(tt.content, "\n")
+			node := valueNode(t, tt.content, tt.path...)
+			start, end, found := scalarSpan(lines, node)
+			if tt.want == "" {
+				if found {
+					t.Errorf("span %q found, want none", lines[node.Line-1][start:end])
+				}
+				return
+			}
+			if !found {
+				t.Fatalf("span not found, want %q", tt.want)
+			}
+			if got := lines[node.Line-1][start:end]; got != tt.want {
+				t.Errorf("span = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequenceEdits(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		path    []string // keys of the list, its first key if empty
+		items   []string
+		want    string // content after the edits, none when the list can't be edited in place
+	}{
+		{
+			name:    "flow list",
+			content: "kafka:\n  brokers: [a:9092, \"b:9092\"] # brokers\n",
+			path:    []string{"kafka", "brokers"},
+			items:   []string{"c:9092"},
+			want:    "kafka:\n  brokers: [c:9092] # brokers\n",
+		},
+		{
+			name:    "empty flow list",
+			content: "topics: []\n",
+			items:   []string{"events", "orders"},
+			want:    "topics: [events, orders]\n",
+		},
+		{
+			name:    "same length",
+			content: "brokers:\n  - a:9092 # first\n  - b:9092\n",
+			items:   []string{"c:9092", "d:9092"},
+			want:    "brokers:\n  - c:9092 # first\n  - d:9092\n",
+		},
+		{
+			name:    "fewer items",
+			content: "brokers:\n  - a:9092\n  - b:9092\n  - c:9092\nnext: 1\n",
+			items:   []string{"d:9092"},
+			want:    "brokers:\n  - d:9092\nnext: 1\n",
+		},
+		{
+			name:    "more items",
+			content: "brokers:\n    - a:9092\nnext: 1\n",
+			items:   []string{"b:9092", "c:9092", "d:9092"},
+			want:    "brokers:\n    - b:9092\n    - c:9092\n    - d:9092\nnext: 1\n",
+		},
+		{
+			name:    "quoted items",
+			content: "topics:\n  - 'a'\n",
+			items:   []string{"true", "a b"},
+			want:    "topics:\n  - \"true\"\n  - a b\n",
+		},
+		{name: "no items", content: "topics:\n  - a\n"},
+		{name: "mapping items", content: "topics:\n  - name: a\n", items: []string{"b"}},
+		{name: "multi-line flow list", content: "topics: [a,\n  b]\n", items: []string{"c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(tt.content, "\n")
+			path := tt.path
+			if path == nil {
+				path = []string{strings.SplitN(tt.content, ":", 2)[0]}
+			}
+			node := valueNode(t, tt.content, path...)
+
+			edits, ok := sequenceEdits(lines, node, tt.items)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("edits %+v, want none", edits)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("list not edited in place")
+			}
+			if got := applyLineEdits(lines, edits); got != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyLineEdits(t *testing.T) {
+	lines := []string{"a: 1, b: 2", "c: 3", "d: 4"}
+	tests := []struct {
+		name  string
+		edits []lineEdit
+		want  string
+	}{
+		{name: "none", want: "a: 1, b: 2\nc: 3\nd: 4"},
+		{
+			name:  "same line in any order",
+			edits: []lineEdit{{Line: 1, Start: 3, End: 4, Text: "10"}, {Line: 1, Start: 9, End: 10, Text: "20"}},
+			want:  "a: 10, b: 20\nc: 3\nd: 4",
+		},
+		{
+			name:  "removed line",
+			edits: []lineEdit{{Line: 2, Remove: true}, {Line: 3, Start: 3, End: 4, Text: "5\nd2: 6"}},
+			want:  "a: 1, b: 2\nd: 5\nd2: 6",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyLineEdits(lines, tt.edits); got != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+			if lines[0] != "a: 1, b: 2" {
+				t.Error("the lines were modified")
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// orgConfigEnv names the environment variable that points to the organization config file
+const orgConfigEnv = "WEBCORE_ORG_CONFIG"
+
+// userConfig holds the settings from the user config file
+type userConfig struct {
+	Hooks    []Hook         `yaml:"hooks"`
+	Defaults answerDefaults `yaml:"defaults"`
+	Presets  []Preset       `yaml:"presets"`
+
+	path string
+}
+
+// answerDefaults are the default answers of a user or organization config file
+type answerDefaults struct {
+	ModulePrefix string   `yaml:"module_prefix"` // e.g. github.com/yourorg, the project directory name is appended
+	Libraries    []string `yaml:"libraries"`
+	Mode         string   `yaml:"mode"`
+	Features     []string `yaml:"features"`
+	Template     string   `yaml:"template"` // git URL or path of the template to clone
+	GitInit      *bool    `yaml:"git_init"`
+
+	GitBranch        string `yaml:"git_branch"`
+	GitCommit        *bool  `yaml:"git_commit"`
+	GitCommitMessage string `yaml:"git_commit_message"`
+	GitAuthor        string `yaml:"git_author"` // "Name <email>"
+
+	Dockerfile *bool  `yaml:"dockerfile"`
+	CI         string `yaml:"ci"`         // github, gitlab, makefile or none
+	Kubernetes string `yaml:"kubernetes"` // manifests, helm or none
+
+	ServiceConfig *bool `yaml:"service_config"`
+	EnvFile       *bool `yaml:"env_file"` // secrets of the service settings in .env
+}
+
+// userConfigPath returns the path of the user config file, $XDG_CONFIG_HOME/webcore-go-install/config.yaml
+// or ~/.config/webcore-go-install/config.yaml on every platform
+func userConfigPath() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(configDir) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "webcore-go-install", "config.yaml"), nil
+}
+
+// loadUserConfig loads the user config file, a missing file results in an empty config
+func loadUserConfig() (*userConfig, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return &userConfig{}, nil
+	}
+	return readConfigFile(path, false)
+}
+
+// loadOrgConfig loads the organization config file named by WEBCORE_ORG_CONFIG, if it is set
+func loadOrgConfig() (*userConfig, error) {
+	path := os.Getenv(orgConfigEnv)
+	if path == "" {
+		return &userConfig{}, nil
+	}
+	return readConfigFile(path, true)
+}
+
+// readConfigFile reads a user or organization config file.
+// A missing file results in an empty config unless required is set.
+func readConfigFile(path string, required bool) (*userConfig, error) {
+	config := &userConfig{path: path}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return config, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+)
+
+// installStep represents a single named step of the installation pipeline
+type installStep struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context, config *Config) error
+	Done        func(config *Config) bool // detects that the step has already been applied, used when resuming
+}
+
+// stepResult holds the outcome of a single step run
+type stepResult struct {
+	Name     string
+	Status   string // "done", "skipped" or "failed"
+	Duration time.Duration
+}
+
+// installSteps is the ordered list of steps applied after all questions are answered
+var installSteps = []installStep{
+	{
+		Name:        "rewrite-main",
+		Description: "Replace module name in webcore/main.go",
+		Run: func(ctx context.Context, config *Config) error {
+			mainGoPath := filepath.Join(config.ProjectDir, "webcore", "main.go")
+			if err := replaceInFile(mainGoPath, "github.com/semanggilab/webcorego-template-app", config.ModuleName); err != nil {
+				return fmt.Errorf("failed to update webcore/main.go: %w", err)
+			}
+			return nil
+		},
+		Done: func(config *Config) bool {
+			return !fileContains(filepath.Join(config.ProjectDir, "webcore", "main.go"), "github.com/semanggilab/webcorego-template-app")
+		},
+	},
+	{
+		Name:        "go-mod",
+		Description: "Update webcore/go.mod with main module name",
+		Run: func(ctx context.Context, config *Config) error {
+			if err := updateWebcoreGoMod(config.ProjectDir, config.ModuleName); err != nil {
+				return fmt.Errorf("failed to update webcore/go.mod: %w", err)
+			}
+			return nil
+		},
+		Done: func(config *Config) bool {
+			content, err := os.ReadFile(filepath.Join(config.ProjectDir, "webcore", "go.mod"))
+			return err == nil && modfile.ModulePath(content) == config.ModuleName
+		},
+	},
+	{
+		Name:        "libraries",
+		Description: "Update webcore/deps/libraries.go with selected libraries",
+		Run: func(ctx context.Context, config *Config) error {
+			if err := updateLibrariesGo(config.ProjectDir, config.SelectedLibraries); err != nil {
+				return fmt.Errorf("failed to update libraries.go: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "go-get",
+		Description: "Install selected libraries with go get",
+		Run: func(ctx context.Context, config *Config) error {
+			if err := installLibraries(ctx, config.ProjectDir, config.SelectedLibraries); err != nil {
+				return fmt.Errorf("failed to install libraries: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "config-files",
+		Description: "Copy example config files",
+		Run: func(ctx context.Context, config *Config) error {
+			if err := copyConfigFiles(ctx, config); err != nil {
+				return fmt.Errorf("failed to copy config files: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "service-settings",
+		Description: "Write the service settings into config.yaml and the secrets into .env if requested",
+		Run: func(ctx context.Context, config *Config) error {
+			if !config.ServiceConfig {
+				return nil
+			}
+			if err := applyServiceSettings(config); err != nil {
+				return fmt.Errorf("failed to write the service settings: %w", err)
+			}
+			return nil
+		},
+		Done: isServiceSettingsDone,
+	},
+	{
+		Name:        "compose",
+		Description: "Generate docker-compose.yaml with the services of the selected libraries",
+		Run: func(ctx context.Context, config *Config) error {
+			if err := generateCompose(ctx, config); err != nil {
+				return fmt.Errorf("failed to generate %s: %w", composeFileName, err)
+			}
+			return nil
+		},
+		Done: func(config *Config) bool {
+			return len(selectedBackingServices(config.SelectedLibraries)) == 0 || fileExists(filepath.Join(config.ProjectDir, composeFileName))
+		},
+	},
+	{
+		Name:        "mode",
+		Description: "Apply project mode (mono-repo or simple)",
+		Run: func(ctx context.Context, config *Config) error {
+			if config.ProjectMode == "mono-repo" {
+				if err := applyMonoRepoMode(config); err != nil {
+					return fmt.Errorf("failed to apply mono-repo mode: %w", err)
+				}
+				return nil
+			}
+
+			if err := applySimpleMode(config); err != nil {
+				return fmt.Errorf("failed to apply simple mode: %w", err)
+			}
+			return nil
+		},
+		Done: func(config *Config) bool {
+			// The dummy module is renamed (mono-repo) or emptied (simple) by this step
+			if config.ProjectMode == "mono-repo" {
+				return fileExists(filepath.Join(config.ProjectDir, "modules", config.FolderName)) &&
+					!fileExists(filepath.Join(config.ProjectDir, "modules", "dummy"))
+			}
+			return fileExists(filepath.Join(config.ProjectDir, "webcore", "app", "module.go"))
+		},
+	},
+	{
+		Name:        "packages",
+		Description: "Update webcore/deps/packages.go",
+		Run: func(ctx context.Context, config *Config) error {
+			if err := updatePackagesGo(config); err != nil {
+				return fmt.Errorf("failed to update packages.go: %w", err)
+			}
+			return nil
+		},
+		Done: func(config *Config) bool {
+			packagesPath := filepath.Join(config.ProjectDir, "webcore", "deps", "packages.go")
+			return fileExists(packagesPath) && !fileContains(packagesPath, "dummy.NewModule()")
+		},
+	},
+	{
+		Name:        "cleanup",
+		Description: "Remove modules/dummy folder if it still exists",
+		Run: func(ctx context.Context, config *Config) error {
+			if err := cleanupDummyFolder(config.ProjectDir); err != nil {
+				return fmt.Errorf("failed to cleanup dummy folder: %w", err)
+			}
+			return nil
+		},
+		Done: func(config *Config) bool {
+			return !fileExists(filepath.Join(config.ProjectDir, "modules", "dummy"))
+		},
+	},
+	{
+		Name:        "go-work",
+		Description: "Update go.work file and run go work sync",
+		Run: func(ctx context.Context, config *Config) error {
+			if err := updateGoWork(ctx, config); err != nil {
+				return fmt.Errorf("failed to update go.work: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "dockerfile",
+		Description: "Generate a Dockerfile and .dockerignore if requested",
+		Run: func(ctx context.Context, config *Config) error {
+			if !config.Dockerfile {
+				return nil
+			}
+			if err := generateDockerfile(ctx, config); err != nil {
+				return fmt.Errorf("failed to generate Dockerfile: %w", err)
+			}
+			return nil
+		},
+		Done: func(config *Config) bool {
+			return !config.Dockerfile || fileExists(filepath.Join(config.ProjectDir, "Dockerfile"))
+		},
+	},
+	{
+		Name:        "ci",
+		Description: "Generate the CI pipeline if requested",
+		Run: func(ctx context.Context, config *Config) error {
+			if config.CI == "" {
+				return nil
+			}
+			if err := generateCI(ctx, config); err != nil {
+				return fmt.Errorf("failed to generate the CI pipeline: %w", err)
+			}
+			return nil
+		},
+		Done: func(config *Config) bool {
+			return config.CI == "" || fileExists(filepath.Join(config.ProjectDir, ciFiles[config.CI]))
+		},
+	},
+	{
+		Name:        "kubernetes",
+		Description: "Generate the Kubernetes manifests or Helm chart if requested",
+		Run: func(ctx context.Context, config *Config) error {
+			if config.Kubernetes == "" {
+				return nil
+			}
+			if err := generateKubernetes(ctx, config); err != nil {
+				return fmt.Errorf("failed to generate the Kubernetes files: %w", err)
+			}
+			return nil
+		},
+		Done: func(config *Config) bool {
+			return config.Kubernetes == "" || fileExists(kubernetesDonePath(config))
+		},
+	},
+	{
+		Name:        "git-init",
+		Description: "Initialize git repository with .gitignore, remote and initial commit if requested",
+		Run: func(ctx context.Context, config *Config) error {
+			if !config.GitInit {
+				return nil
+			}
+			if err := initGit(ctx, config); err != nil {
+				return fmt.Errorf("failed to initialize git: %w", err)
+			}
+			return nil
+		},
+		Done: isGitDone,
+	},
+}
+
+// stepNames returns the names of all install steps in order
+func stepNames() []string {
+	names := make([]string, len(installSteps))
+	for i, step := range installSteps {
+		names[i] = step.Name
+	}
+	return names
+}
+
+// isStepName checks if name is the name of an install step
+func isStepName(name string) bool {
+	for _, step := range installSteps {
+		if step.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStepList parses a comma separated list of step names and validates each of them
+func parseStepList(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	names := make([]string, 0)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !isStepName(name) {
+			return nil, fmt.Errorf("unknown step %q (available: %s)", name, strings.Join(stepNames(), ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// selectSteps filters the install steps using the --only and --skip lists
+func selectSteps(only, skip []string) []installStep {
+	onlyMap := make(map[string]bool)
+	for _, name := range only {
+		onlyMap[name] = true
+	}
+	skipMap := make(map[string]bool)
+	for _, name := range skip {
+		skipMap[name] = true
+	}
+
+	selected := make([]installStep, 0, len(installSteps))
+	for _, step := range installSteps {
+		if len(onlyMap) > 0 && !onlyMap[step.Name] {
+			continue
+		}
+		if skipMap[step.Name] {
+			continue
+		}
+		selected = append(selected, step)
+	}
+	return selected
+}
+
+// excludedSteps returns the names of all steps that aren't in names
+func excludedSteps(names []string) []string {
+	included := make(map[string]bool)
+	for _, name := range names {
+		included[name] = true
+	}
+
+	excluded := make([]string, 0)
+	for _, name := range stepNames() {
+		if !included[name] {
+			excluded = append(excluded, name)
+		}
+	}
+	return excluded
+}
+
+// stepKey is the context key of the name of the running step
+type stepKey struct{}
+
+// withStep returns a context carrying the name of the running step, the command events report it
+func withStep(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, stepKey{}, name)
+}
+
+// stepOf returns the name of the running step, empty outside of the steps
+func stepOf(ctx context.Context) string {
+	name, _ := ctx.Value(stepKey{}).(string)
+	return name
+}
+
+// runSteps runs the given steps in order together with their hooks and reports the duration of each of them.
+// Progress is recorded in the checkpoint, when resuming the steps finished earlier are skipped.
+func runSteps(ctx context.Context, config *Config, steps []installStep, hooks []Hook, cp *checkpoint) ([]stepResult, error) {
+	selected := make(map[string]bool)
+	for _, step := range steps {
+		selected[step.Name] = true
+	}
+
+	results := make([]stepResult, 0, len(installSteps))
+	for _, step := range installSteps {
+		if !selected[step.Name] {
+			result := stepResult{Name: step.Name, Status: "skipped"}
+			showStep(result, fmt.Sprintf("⏭️ Skipping step %s", step.Name))
+			results = append(results, result)
+			continue
+		}
+
+		// The failed step always runs again, other steps are skipped when recorded or detected as done
+		if cp.resume && step.Name != cp.FailedStep && (cp.isCompleted(step.Name) || step.Done != nil && step.Done(config)) {
+			result := stepResult{Name: step.Name, Status: "skipped"}
+			showStep(result, fmt.Sprintf("⏭️ Step %s already completed, skipping", step.Name))
+			results = append(results, result)
+			if err := cp.markCompleted(step.Name); err != nil {
+				return results, fmt.Errorf("failed to save checkpoint: %w", err)
+			}
+			continue
+		}
+
+		// Stop before the next step when interrupted, the checkpoint keeps the installation resumable
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		start := time.Now()
+		stepCtx := withStep(ctx, step.Name)
+		err := runHooks(stepCtx, config, hooks, step.Name, "before")
+		if err == nil {
+			err = step.Run(stepCtx, config)
+		}
+		if err == nil {
+			err = runHooks(stepCtx, config, hooks, step.Name, "after")
+		}
+		duration := time.Since(start)
+
+		if err != nil {
+			result := stepResult{Name: step.Name, Status: "failed", Duration: duration}
+			showStep(result, fmt.Sprintf("❌ Step %s failed after %s", step.Name, duration.Round(time.Millisecond)))
+			results = append(results, result)
+			if cpErr := cp.markFailed(step.Name, err); cpErr != nil {
+				warn(step.Name, fmt.Sprintf("Failed to save checkpoint: %v", cpErr))
+			}
+			return results, stepError(step.Name, err)
+		}
+
+		result := stepResult{Name: step.Name, Status: "done", Duration: duration}
+		showStep(result, fmt.Sprintf("⏱️ Step %s completed in %s", step.Name, duration.Round(time.Millisecond)))
+		results = append(results, result)
+		if err := cp.markCompleted(step.Name); err != nil {
+			return results, fmt.Errorf("failed to save checkpoint: %w", err)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/mattn/go-tty"
+	"github.com/yarlson/tap"
+	"golang.org/x/sys/unix"
+)
+
+// keyPollInterval is how often the key reader checks if its prompt ended while no key is pressed
+const keyPollInterval = 50 * time.Millisecond
+
+// promptTerminal is the terminal while a prompt is showing. It is opened in raw mode when the prompt starts and
+// closed, which restores the terminal settings, when it ends: the commands run between the prompts (git, go get,
+// hooks) get the terminal with the user's settings and their input isn't read by the installer.
+// The prompts get it as their input and output, so the prompt library never opens the terminal itself:
+// it would install a signal handler that exits the process, while the installer handles interrupts
+// through newInterruptContext.
+type promptTerminal struct {
+	tty     *tty.TTY
+	mu      sync.Mutex
+	handler func(string, tap.Key) // keypress handler of the prompt
+	resize  []func()
+	winch   chan os.Signal
+	stop    chan struct{} // closed to stop the key reader
+	done    chan struct{} // closed when the key reader stopped
+	once    sync.Once
+}
+
+// activeTerminal is the terminal of the prompt that is showing, closed by closePromptTerminal on exit
+var (
+	activeTerminal *promptTerminal
+	activeMu       sync.Mutex
+)
+
+// openPromptTerminal opens the terminal of the process for a prompt
+func openPromptTerminal() (*promptTerminal, error) {
+	return openTerminalDevice("/dev/tty")
+}
+
+// openTerminalDevice opens a terminal device in raw mode and reads its keys until it is closed
+func openTerminalDevice(path string) (*promptTerminal, error) {
+	t, err := tty.OpenDevice(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the terminal: %w", err)
+	}
+
+	terminal := &promptTerminal{
+		tty:   t,
+		winch: make(chan os.Signal, 1),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go terminal.readKeys()
+	signal.Notify(terminal.winch, syscall.SIGWINCH)
+	go terminal.watchResize()
+
+	activeMu.Lock()
+	activeTerminal = terminal
+	activeMu.Unlock()
+	return terminal, nil
+}
+
+// closePromptTerminal restores the terminal of a prompt that is still showing, every exit goes through it
+func closePromptTerminal() {
+	activeMu.Lock()
+	terminal := activeTerminal
+	activeMu.Unlock()
+	if terminal != nil {
+		_ = terminal.Close()
+	}
+}
+
+// Close stops reading keys and restores the terminal settings from before the prompt
+func (t *promptTerminal) Close() error {
+	var err error
+	t.once.Do(func() {
+		close(t.stop)
+		<-t.done
+		signal.Stop(t.winch)
+		close(t.winch)
+
+		in := t.tty.Input()
+		err = errors.Join(t.tty.Close(), in.Close())
+
+		activeMu.Lock()
+		if activeTerminal == t {
+			activeTerminal = nil
+		}
+		activeMu.Unlock()
+	})
+	return err
+}
+
+// readKeys passes the keys to the prompt until the terminal is closed. It waits for input with a timeout,
+// so it never blocks in a read after the prompt ended.
+func (t *promptTerminal) readKeys() {
+	defer close(t.done)
+	for {
+		select {
+		case <-t.stop:
+			return
+		default:
+		}
+		if !t.tty.Buffered() && !t.waitInput(keyPollInterval) {
+			continue
+		}
+		r, err := t.tty.ReadRune()
+		if err != nil {
+			return
+		}
+		t.dispatch(t.parseKey(r))
+	}
+}
+
+// waitInput waits up to timeout for input on the terminal
+func (t *promptTerminal) waitInput(timeout time.Duration) bool {
+	fd := int(t.tty.Input().Fd())
+	var set unix.FdSet
+	set.Set(fd)
+	tv := unix.NsecToTimeval(timeout.Nanoseconds())
+	n, err := unix.Select(fd+1, &set, nil, nil, &tv)
+	return err == nil && n > 0
+}
+
+// parseKey converts a rune read from the terminal to a key, reading the rest of an escape sequence
+func (t *promptTerminal) parseKey(r rune) tap.Key {
+	switch r {
+	case 27:
+		// A lone Esc has nothing after it, an escape sequence follows at once
+		if !t.tty.Buffered() && !t.waitInput(10*time.Millisecond) {
+			return tap.Key{Name: "escape"}
+		}
+		if next, err := t.tty.ReadRune(); err != nil || next != '[' {
+			return tap.Key{Name: "escape"}
+		}
+		code, err := t.tty.ReadRune()
+		if err != nil {
+			return tap.Key{Name: "escape"}
+		}
+		switch code {
+		case 'A':
+			return tap.Key{Name: "up"}
+		case 'B':
+			return tap.Key{Name: "down"}
+		case 'C':
+			return tap.Key{Name: "right"}
+		case 'D':
+			return tap.Key{Name: "left"}
+		case '3':
+			_, _ = t.tty.ReadRune() // trailing ~ of the delete key
+			return tap.Key{Name: "delete"}
+		}
+		return tap.Key{Name: "escape"}
+	case 13:
+		return tap.Key{Name: "return"}
+	case 127, 8:
+		return tap.Key{Name: "backspace"}
+	case 9:
+		return tap.Key{Name: "tab"}
+	case 32:
+		return tap.Key{Name: "space", Rune: ' '}
+	case 3:
+		return tap.Key{Name: "c", Rune: 'c', Ctrl: true}
+	}
+	if r >= 32 && r <= 126 {
+		return tap.Key{Name: string(r), Rune: r}
+	}
+	return tap.Key{Rune: r}
+}
+
+// dispatch passes a key to the handler of the prompt
+func (t *promptTerminal) dispatch(key tap.Key) {
+	t.mu.Lock()
+	handler := t.handler
+	t.mu.Unlock()
+	if handler == nil {
+		return
+	}
+
+	char := ""
+	if key.Rune != 0 {
+		char = string(key.Rune)
+	}
+	handler(char, key)
+}
+
+// watchResize tells the prompt that the terminal was resized, so it redraws
+func (t *promptTerminal) watchResize() {
+	for range t.winch {
+		t.mu.Lock()
+		handlers := append([]func(){}, t.resize...)
+		t.mu.Unlock()
+		for _, handler := range handlers {
+			handler()
+		}
+	}
+}
+
+// Read is never used, the prompts receive the keys through On
+func (t *promptTerminal) Read(p []byte) (int, error) {
+	return 0, nil
+}
+
+// On registers the keypress handler of the prompt
+func (t *promptTerminal) On(event string, handler func(string, tap.Key)) {
+	if event != "keypress" {
+		return
+	}
+	t.mu.Lock()
+	t.handler = handler
+	t.mu.Unlock()
+}
+
+// Write renders the prompts on stdout
+func (t *promptTerminal) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+// resizeOutput is the output side of the terminal, its On registers resize handlers
+type resizeOutput struct{ *promptTerminal }
+
+func (o resizeOutput) On(event string, handler func()) {
+	if event != "resize" {
+		return
+	}
+	o.mu.Lock()
+	o.resize = append(o.resize, handler)
+	o.mu.Unlock()
+}
+
+func (o resizeOutput) Emit(event string) {}
+
+// promptInput is the input of a single prompt, it records if the prompt was cancelled with Esc or Ctrl-C
+type promptInput struct {
+	tap.Reader
+	cancelled atomic.Bool
+}
+
+// newPromptInput wraps the keys of in for one prompt
+func newPromptInput(in tap.Reader) *promptInput {
+	return &promptInput{Reader: in}
+}
+
+// On registers the keypress handler of the prompt, noting the cancel keys before the prompt handles them
+func (in *promptInput) On(event string, handler func(string, tap.Key)) {
+	if event != "keypress" {
+		in.Reader.On(event, handler)
+		return
+	}
+	in.Reader.On(event, func(char string, key tap.Key) {
+		if isCancelKey(char, key) {
+			in.cancelled.Store(true)
+		}
+		handler(char, key)
+	})
+}
+
+// isCancelKey checks if a key cancels a prompt, the same keys the prompts cancel on
+func isCancelKey(char string, key tap.Key) bool {
+	return char == "\x03" || (key.Ctrl && key.Name == "c") || key.Name == "escape"
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yarlson/tap"
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo terminal and returns its controlling side and the path of the terminal side
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("no pseudo terminals: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	fd := int(master.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		t.Skipf("cannot unlock the pseudo terminal: %v", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		t.Skipf("cannot get the pseudo terminal number: %v", err)
+	}
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+// termiosOf returns the settings of a terminal device
+func termiosOf(t *testing.T, path string) unix.Termios {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	termios, err := unix.IoctlGetTermios(int(f.Fd()), unix.TCGETS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *termios
+}
+
+// assertRestored fails when the terminal settings differ from before, e.g. echo or line editing stayed off
+func assertRestored(t *testing.T, path string, before unix.Termios) {
+	t.Helper()
+	after := termiosOf(t, path)
+	if after.Lflag != before.Lflag || after.Iflag != before.Iflag {
+		t.Errorf("terminal not restored: lflag %#x iflag %#x, want lflag %#x iflag %#x", after.Lflag, after.Iflag, before.Lflag, before.Iflag)
+	}
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	if activeTerminal != nil {
+		t.Error("the closed terminal is still the active one")
+	}
+}
+
+// waitListening waits until the prompt registered its keypress handler
+func waitListening(t *testing.T, terminal *promptTerminal) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		terminal.mu.Lock()
+		listening := terminal.handler != nil
+		terminal.mu.Unlock()
+		if listening {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("the prompt didn't start")
+}
+
+func TestPromptTerminalRestoredAfterCancel(t *testing.T) {
+	master, path := openPTY(t)
+	before := termiosOf(t, path)
+
+	terminal, err := openTerminalDevice(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw := termiosOf(t, path); raw.Lflag&(unix.ECHO|unix.ICANON) != 0 {
+		t.Fatalf("terminal not in raw mode during the prompt: lflag %#x", raw.Lflag)
+	}
+
+	in := newPromptInput(terminal)
+	result := make(chan string, 1)
+	go func() {
+		result <- tap.Text(context.Background(), tap.TextOptions{Message: "Answer", Input: in, Output: tap.NewMockWritable()})
+	}()
+	waitListening(t, terminal)
+	if _, err := master.Write([]byte{27}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-result:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Esc didn't cancel the prompt")
+	}
+	if !in.cancelled.Load() {
+		t.Error("the prompt wasn't recorded as cancelled")
+	}
+
+	if err := terminal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertRestored(t, path, before)
+}
+
+func TestPromptTerminalRestoredOnExit(t *testing.T) {
+	_, path := openPTY(t)
+	before := termiosOf(t, path)
+
+	terminal, err := openTerminalDevice(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closePromptTerminal()
+	assertRestored(t, path, before)
+
+	select {
+	case <-terminal.done:
+	default:
+		t.Error("keys are still read after the terminal was closed")
+	}
+}